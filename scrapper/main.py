@@ -1,5 +1,6 @@
 import asyncio
 import base64
+import hashlib
 import json
 import logging
 import os
@@ -17,6 +18,7 @@ from webdriver_manager.firefox import GeckoDriverManager
 
 logger = logging.getLogger(__name__)
 screenshot_file_name = 'screenshot.png'
+object_store_bucket = 'objects'
 
 
 class Result:
@@ -98,23 +100,34 @@ def do_web_scraping() -> Result:
     return result
 
 
-async def notify(nats_host: str, msg: str, debug: bool) -> None:
-    b64 = bytes()
-    try:
-        os.stat(screenshot_file_name)
-        image = open(screenshot_file_name, 'rb')
-        b64 = base64.b64encode(image.read())
-
-    except FileNotFoundError:
-        logger.warning("there is no screenshot to send")
-
+async def notify(nats_host: str, msg: str, debug: bool, status: str) -> None:
     nc = await nats.connect(nats_host)
 
     a = {
         "debug": debug,
+        "status": status,
         "message": msg,
-        "image": b64.decode("utf-8"),
     }
+
+    try:
+        os.stat(screenshot_file_name)
+        with open(screenshot_file_name, 'rb') as image:
+            data = image.read()
+
+        try:
+            # The screenshot may exceed the NATS max payload, so the message only carries a reference to it.
+            name = hashlib.sha256(data).hexdigest()
+            obs = await nc.jetstream().object_store(object_store_bucket)
+            await obs.put(name, data)
+            a["image_ref"] = name
+
+        except Exception as object_store_exception:
+            logger.warning("error uploading screenshot, sending it inline", exc_info=object_store_exception)
+            a["image"] = base64.b64encode(data).decode("utf-8")
+
+    except FileNotFoundError:
+        logger.warning("there is no screenshot to send")
+
     j = json.dumps(a)
 
     await nc.publish("scrapper.result", j.encode("utf-8"))
@@ -140,11 +153,11 @@ if __name__ == '__main__':
         # No hours available text found in the booking webpage.
         logger.info("there are no available hours")
         asyncio.run(
-            notify(nats_server_host, "There are no available hours", True))
+            notify(nats_server_host, "There are no available hours", True, "unavailable"))
 
     elif r.status == ResultStatus.ERROR:
         message = "Error validating hour availability: " + r.message
-        asyncio.run(notify(nats_server_host, message, True))
+        asyncio.run(notify(nats_server_host, message, True, "failed"))
 
     else:
-        asyncio.run(notify(nats_server_host, "There are hours available", False))
+        asyncio.run(notify(nats_server_host, "There are hours available", False, "available"))
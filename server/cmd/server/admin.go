@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/api"
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/notification"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// resultsFollowInterval is how often `results tail -follow` looks for new results.
+const resultsFollowInterval = 5 * time.Second
+
+// admin is what the admin subcommands operate on: the database of a stopped server or the admin API of a
+// running one.
+type admin interface {
+	Subscribers() ([]int64, error)
+	AddSubscriber(id int64) error
+	RemoveSubscriber(id int64) error
+	ManageDebug(enable bool) error
+	Results(limit int) ([]repository.Result, error)
+	SendTest(ctx context.Context, chatID int64) error
+	Compact() error
+	Close() error
+}
+
+// offlineAdmin opens the database directly, the bot is only created to send the test messages. Creating it
+// doesn't call the Bot API, and the test messages only read the chat profile. The subscription and debug changes
+// are audited, as the admin API ones.
+type offlineAdmin struct {
+	storage
+	config *config.Store
+	audit  *audit.Log
+}
+
+func (a offlineAdmin) AddSubscriber(id int64) error {
+	err := a.storage.AddSubscriber(id)
+	a.audit.Record(repository.AuditActorOfflineCLI, "subs add", strconv.FormatInt(id, 10), err)
+
+	return err
+}
+
+func (a offlineAdmin) RemoveSubscriber(id int64) error {
+	err := a.storage.RemoveSubscriber(id)
+	a.audit.Record(repository.AuditActorOfflineCLI, "subs remove", strconv.FormatInt(id, 10), err)
+
+	return err
+}
+
+func (a offlineAdmin) ManageDebug(enable bool) error {
+	action := "debug off"
+	if enable {
+		action = "debug on"
+	}
+
+	err := a.storage.ManageDebug(enable)
+	a.audit.Record(repository.AuditActorOfflineCLI, action, "", err)
+
+	return err
+}
+
+func (a offlineAdmin) SendTest(ctx context.Context, chatID int64) error {
+	cfg := a.config.Current()
+
+	bot, err := telegrambot.NewBot(cfg.Telegram.BotToken, "", nil)
+	if err != nil {
+		return fmt.Errorf("error creating telegram bot: %w", err)
+	}
+
+	languages := notification.NewLanguages(a.storage, a.config)
+	return notification.NewTestSender(bot, languages).SendTest(ctx, chatID)
+}
+
+// adminCommand holds the flags shared by the admin subcommands.
+type adminCommand struct {
+	flags  *flag.FlagSet
+	loader *config.Loader
+	apiURL *string
+}
+
+func newAdminCommand(name string) *adminCommand {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+
+	return &adminCommand{
+		flags:  flags,
+		loader: config.NewLoader(flags),
+		apiURL: flags.String("api", "", "admin API URL of the running server (e.g. http://localhost:8080), "+
+			"it uses the owner token; the database is opened directly if empty"),
+	}
+}
+
+// open parses the flags and returns the admin to operate on along with the positional arguments.
+func (c *adminCommand) open(args []string) (admin, []string, error) {
+	_ = c.flags.Parse(args)
+
+	cfg, err := c.loader.Load()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if *c.apiURL != "" {
+		return api.NewClient(*c.apiURL, cfg.HTTP.OwnerToken), c.flags.Args(), nil
+	}
+
+	db, err := openStorage(cfg.DB)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening database, use -api if the server is running: %w", err)
+	}
+
+	return offlineAdmin{storage: db, config: config.NewStore(c.loader, cfg), audit: audit.NewLog(db)}, c.flags.Args(), nil
+}
+
+// action returns the subcommand action, which must be one of the given ones, and its arguments.
+func action(args []string, usage string, actions ...string) (string, []string, error) {
+	if len(args) == 0 || !slices.Contains(actions, args[0]) {
+		return "", nil, fmt.Errorf("usage: %s", usage)
+	}
+
+	return args[0], args[1:], nil
+}
+
+func chatIDArg(args []string, usage string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("usage: %s", usage)
+	}
+
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chat ID '%s'", args[0])
+	}
+
+	return id, nil
+}
+
+func runSubs(args []string) error {
+	const usage = "server subs list|add|remove [flags] [<chat ID>]"
+
+	name, args, err := action(args, usage, "list", "add", "remove")
+	if err != nil {
+		return err
+	}
+
+	a, args, err := newAdminCommand("subs " + name).open(args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	if name == "list" {
+		subs, err := a.Subscribers()
+		if err != nil {
+			return err
+		}
+
+		slices.Sort(subs)
+		for _, sub := range subs {
+			fmt.Println(sub)
+		}
+
+		return nil
+	}
+
+	id, err := chatIDArg(args, usage)
+	if err != nil {
+		return err
+	}
+
+	if name == "add" {
+		return a.AddSubscriber(id)
+	}
+
+	return a.RemoveSubscriber(id)
+}
+
+func runDebug(args []string) error {
+	name, args, err := action(args, "server debug on|off [flags]", "on", "off")
+	if err != nil {
+		return err
+	}
+
+	a, _, err := newAdminCommand("debug " + name).open(args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.ManageDebug(name == "on")
+}
+
+func runSendTest(args []string) error {
+	const usage = "server send-test [flags] <chat ID>"
+
+	a, args, err := newAdminCommand("send-test").open(args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	id, err := chatIDArg(args, usage)
+	if err != nil {
+		return err
+	}
+
+	return a.SendTest(context.Background(), id)
+}
+
+func runResults(args []string) error {
+	name, args, err := action(args, "server results tail [flags]", "tail")
+	if err != nil {
+		return err
+	}
+
+	command := newAdminCommand("results " + name)
+	limit := command.flags.Int("n", 10, "number of results to show")
+	follow := command.flags.Bool("follow", false, "keep showing the new results")
+
+	a, _, err := command.open(args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	results, err := a.Results(*limit)
+	if err != nil {
+		return err
+	}
+
+	var lastID uint64
+	for _, result := range slices.Backward(results) {
+		printResult(result)
+		lastID = max(lastID, result.ID)
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(resultsFollowInterval):
+		}
+
+		results, err := a.Results(*limit)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range slices.Backward(results) {
+			if result.ID > lastID {
+				printResult(result)
+				lastID = result.ID
+			}
+		}
+	}
+}
+
+func printResult(result repository.Result) {
+	var flags string
+	if result.Debug {
+		flags += " [debug]"
+	}
+
+	if result.LayoutChanged {
+		flags += " [layout changed]"
+	}
+
+	fmt.Printf("#%d %s %s%s: %s\n",
+		result.ID, result.CreatedAt.Format(time.RFC3339), result.Target, flags, result.Message,
+	)
+}
+
+func runDB(args []string) error {
+	name, args, err := action(args, "server db compact [flags]", "compact")
+	if err != nil {
+		return err
+	}
+
+	a, _, err := newAdminCommand("db " + name).open(args)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+
+	return a.Compact()
+}
+
+func runConfig(args []string) error {
+	name, args, err := action(args, "server config check [flags]", "check")
+	if err != nil {
+		return err
+	}
+
+	flags := flag.NewFlagSet("config "+name, flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	_ = flags.Parse(args)
+
+	if _, err := loader.Load(); err != nil {
+		return err
+	}
+
+	fmt.Println("the configuration is valid")
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/skryde/booking-check/server/internal/config"
+)
+
+// runBackup writes a backup of the configured database, which must not be in use by a running server; use the
+// GET /backup endpoint for those. The backup SHA-256 is written to the <output>.sha256 file.
+func runBackup(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	output := flags.String("output", "", "backup file, the standard output if empty")
+	since := flags.Uint64("since", 0, "version returned by the previous backup for an incremental one, 0 for a full one")
+	_ = flags.Parse(args)
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStorage(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("error opening database, is the server running? %w", err)
+	}
+	defer db.Close()
+
+	// Checked before creating the output file, which would be left empty.
+	if *since != 0 && !db.IncrementalBackups() {
+		return fmt.Errorf("the %s database only supports full backups, -since must be 0", cfg.DB.Driver)
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating backup file: %w", err)
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	hash := sha256.New()
+	version, err := db.Backup(io.MultiWriter(w, hash), *since)
+	if err != nil {
+		return err
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if *output != "" {
+		// Same format as sha256sum, so the backups can be checked with it too.
+		line := fmt.Sprintf("%s  %s\n", sum, filepath.Base(*output))
+		if err := os.WriteFile(*output+".sha256", []byte(line), 0o644); err != nil {
+			return fmt.Errorf("error writing backup checksum: %w", err)
+		}
+	}
+
+	slog.Info("backup done, pass its version as is to -since for the next incremental backup",
+		slog.Uint64("version", version),
+		slog.String("sha256", sum),
+	)
+
+	return nil
+}
+
+// runRestore loads a backup into the configured database path, which must be empty unless restoring an incremental
+// backup. The backup is verified against its SHA-256 before loading it.
+func runRestore(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	input := flags.String("input", "", "backup file (required)")
+	checksum := flags.String("sha256", "", "backup SHA-256, read from the <input>.sha256 file if empty")
+	incremental := flags.Bool("incremental", false, "load an incremental backup over the restored database")
+	_ = flags.Parse(args)
+
+	if *input == "" {
+		return errors.New("the -input flag is required")
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	expected := *checksum
+	if expected == "" {
+		line, err := os.ReadFile(*input + ".sha256")
+		if err != nil {
+			return fmt.Errorf("error reading backup checksum, set it with -sha256: %w", err)
+		}
+
+		expected, _, _ = strings.Cut(strings.TrimSpace(string(line)), " ")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		return fmt.Errorf("error opening backup file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("error reading backup file: %w", err)
+	}
+
+	if sum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(sum, expected) {
+		return fmt.Errorf("backup checksum mismatch: got %s, expected %s", sum, expected)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error reading backup file: %w", err)
+	}
+
+	if err := restoreStorage(cfg.DB, file, *incremental); err != nil {
+		return err
+	}
+
+	slog.Info("backup restored", slog.String("db_path", cfg.DB.Path))
+
+	return nil
+}
@@ -4,117 +4,259 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
-	"os"
-	"strconv"
 
+	"github.com/skryde/booking-check/server/internal/access"
 	"github.com/skryde/booking-check/server/internal/api"
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
 	"github.com/skryde/booking-check/server/internal/notification"
 	"github.com/skryde/booking-check/server/internal/platform/queue"
-	"github.com/skryde/booking-check/server/internal/platform/storage/badger"
 	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/retention"
+	"github.com/skryde/booking-check/server/internal/screenshot"
 )
 
 const (
 	botDescription = `This bot will try to help you getting a Montevideo's Spain Consulate booking hour by notifying you when the booking system shows hour availability.
 
-Privacy: at the moment you /subscribe to these notifications, I will only save your Telegram user ID.
+Privacy: I save your Telegram user ID, language, /settings, /subscribe target, answers to the notifications and when you were notified, and I log the commands you send.
 
-Disclaimer: this bot was not created by the Spain Consulate and is not an official communication channel of them; this is just a simple bot that will send you a message when it detects hour availability in the booking system.`
+Disclaimer: this bot was not created by the Spain Consulate and is not an official channel; it just sends you a message when it detects hour availability.`
 )
 
-func buildConfiguration() (configuration, error) {
-	readOSEnv := func(key string) (string, error) {
-		value := os.Getenv(key)
-		if value == "" {
-			return "", fmt.Errorf("%s environment variable not set", key)
-		}
+func buildDependencies(ctx context.Context, cfgStore *config.Store, _queue *queue.Queue) (dependencies, error) {
+	cfg := cfgStore.Current()
 
-		return value, nil
+	db, err := openStorage(cfg.DB)
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error creating database instance: %w", err)
 	}
 
-	dbPath, err := readOSEnv("DB_PATH")
+	archive := screenshot.NewArchive(db, retentionPolicy(cfg))
+	resultsRetention := retention.NewJob("results", db.DeleteResultsBefore, resultsRetentionPolicy(cfg))
+	auditRetention := retention.NewJob("audit entries", db.DeleteAuditEntriesBefore, auditRetentionPolicy(cfg))
+	auditLog := audit.NewLog(db)
+
+	policy := access.NewPolicy(db, cfgStore)
+	policy.Require("/enabledebug", repository.RoleOperator)
+	policy.Require("/disabledebug", repository.RoleOperator)
+	policy.Require("/status", repository.RoleViewer)
+	policy.Require("/grant", repository.RoleOwner)
+	policy.Require("/revoke", repository.RoleOwner)
+	policy.Require("/roles", repository.RoleOwner)
+	policy.Require("/broadcast", repository.RoleAdmin)
+	// The preview buttons are checked as well, the admin may have lost the role since the preview was sent.
+	policy.Require(notification.BroadcastCallbackPrefix, repository.RoleAdmin)
+	policy.Require("/outcomes", repository.RoleOwner)
+	policy.Require("/audit", repository.RoleOwner)
+
+	botSubsHandler := notification.NewBotSubscriptionHandler(db, cfgStore)
+	botRolesHandler := notification.NewBotRolesHandler(policy)
+	languages := notification.NewLanguages(db, cfgStore)
+	botLanguageHandler := notification.NewBotLanguageHandler(languages)
+	bot, err := telegrambot.NewBot(cfg.Telegram.BotToken, botDescription, botSubsHandler.Start)
 	if err != nil {
-		return configuration{}, err
+		return dependencies{}, fmt.Errorf("error creating telegram bot: %w", err)
 	}
 
-	botToken, err := readOSEnv("TELEGRAM_BOT_TOKEN")
-	if err != nil {
-		return configuration{}, err
+	commandLimiter := telegrambot.NewUserRateLimiter(cfg.RateLimits.CommandsPerMinute, cfg.RateLimits.CommandsBurst)
+	bot.Use(
+		telegrambot.Recover(),
+		telegrambot.Logging(),
+		telegrambot.Audit(auditLog),
+		telegrambot.Metrics(),
+		commandLimiter.Middleware(),
+		telegrambot.Authorize(policy),
+	)
+	bot.SetPermissions(policy)
+	bot.SetMenuStore(db)
+	bot.SetLanguageResolver(languages)
+
+	// The admin command menus depend on the roles, publish them again whenever the roles change.
+	refreshMenus := func() {
+		err := bot.RefreshCommandMenus(ctx)
+		if err != nil {
+			slog.Error("error refreshing telegram bot command menus", slog.Any("error", err))
+		}
 	}
+	policy.OnChange(refreshMenus)
+
+	bot.SetRateLimit(cfg.RateLimits.MessagesPerSecond, cfg.RateLimits.Burst)
+	cfgStore.OnChange(func(cfg config.Config) {
+		bot.SetRateLimit(cfg.RateLimits.MessagesPerSecond, cfg.RateLimits.Burst)
+		commandLimiter.SetLimit(cfg.RateLimits.CommandsPerMinute, cfg.RateLimits.CommandsBurst)
+		archive.SetPolicy(retentionPolicy(cfg))
+		resultsRetention.SetPolicy(resultsRetentionPolicy(cfg))
+		auditRetention.SetPolicy(auditRetentionPolicy(cfg))
+		refreshMenus()
+	})
 
-	botOwnerID, err := readOSEnv("TELEGRAM_BOT_OWNER_ID")
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/subscribe",
+		Description: "Subscribe to Spain Consulate Hour check",
+		Args: []telegrambot.Arg{
+			{Name: "target", Type: telegrambot.ArgString, Optional: true},
+		},
+		Handler: botSubsHandler.Subscribe,
+	})
 	if err != nil {
-		botOwnerID = "-1"
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	ownerID, err := strconv.ParseInt(botOwnerID, 10, 64)
+	err = bot.RegisterCommandHandler("/unsubscribe",
+		"Unsubscribe from Spain Consulate Hour check",
+		botSubsHandler.Unsubscribe,
+	)
 	if err != nil {
-		return configuration{}, fmt.Errorf("invalid '%s' owner Telegram ID: %w", botOwnerID, err)
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	return configuration{
-			dbPath:             dbPath,
-			telegramBotToken:   botToken,
-			telegramBotOwnerID: ownerID,
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/language",
+		Description: "Change the bot language",
+		Args: []telegrambot.Arg{
+			{Name: "language", Type: telegrambot.ArgString, Optional: true, Choices: notification.LanguageChoices()},
 		},
-		nil
-}
-
-func buildDependencies(ctx context.Context, _queue *queue.Queue) (dependencies, error) {
-	cfg, err := buildConfiguration()
+		Handler: botLanguageHandler.Language,
+	})
 	if err != nil {
-		return dependencies{}, fmt.Errorf("failed to build configuration: %w", err)
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	db, err := badger.NewDB(cfg.dbPath)
+	botSettingsHandler := notification.NewBotSettingsHandler(db, cfgStore)
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/settings",
+		Description: "Change your notification settings",
+		Args: []telegrambot.Arg{
+			{Name: "setting", Type: telegrambot.ArgString, Optional: true, Choices: notification.SettingNames()},
+			{Name: "value", Type: telegrambot.ArgString, Optional: true},
+		},
+		Handler: botSettingsHandler.Settings,
+	})
 	if err != nil {
-		return dependencies{}, fmt.Errorf("error creating database instance: %w", err)
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
+	bot.RegisterCallbackHandler(notification.SettingsCallbackPrefix, botSettingsHandler.Callback)
 
-	botSubsHandler := notification.NewBotSubscriptionHandler(db, cfg.telegramBotOwnerID)
-	bot, err := telegrambot.NewBot(cfg.telegramBotToken, botDescription, botSubsHandler.Start)
+	botOutcomeHandler := notification.NewBotOutcomeHandler(db)
+	err = bot.RegisterCommandHandler("/gotit",
+		"Tell us you got your appointment",
+		botOutcomeHandler.GotIt,
+	)
 	if err != nil {
-		return dependencies{}, fmt.Errorf("error creating telegram bot: %w", err)
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
+	bot.RegisterCallbackHandler(notification.OutcomeCallbackPrefix, botOutcomeHandler.Callback)
 
-	err = bot.RegisterCommandHandler("/subscribe",
-		"Subscribe to Spain Consulate Hour check",
-		botSubsHandler.Subscribe,
+	botLastCheckHandler := notification.NewBotLastCheckHandler(db, cfgStore)
+	err = bot.RegisterCommandHandler("/lastcheck",
+		"Show the latest check of each page",
+		botLastCheckHandler.LastCheck,
 	)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	err = bot.RegisterCommandHandler("/unsubscribe",
-		"Unsubscribe from Spain Consulate Hour check",
-		botSubsHandler.Unsubscribe,
+	botStatsHandler := notification.NewBotStatsHandler(db, cfgStore)
+	err = bot.RegisterCommandHandler("/stats",
+		"Show when the hours are usually available",
+		botStatsHandler.Stats,
 	)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	err = bot.RegisterCommandHandler("/enabledebug", "",
+	err = bot.RegisterCommandHandler("/enabledebug",
+		"Send the debug results to the owners",
 		botSubsHandler.EnableDebug,
 	)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	err = bot.RegisterCommandHandler("/disabledebug", "",
+	err = bot.RegisterCommandHandler("/disabledebug",
+		"Stop sending the debug results to the owners",
 		botSubsHandler.DisableDebug,
 	)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	err = bot.RegisterCommandHandler("/status", "",
+	err = bot.RegisterCommandHandler("/status",
+		"Show the subscribers and debug status",
 		botSubsHandler.Status,
 	)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error registering command: %w", err)
 	}
 
-	queueHandler := notification.NewQueueHandler(ctx, bot, db, _queue, cfg.telegramBotOwnerID)
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/grant",
+		Description: "Grant a role to a user",
+		Args: []telegrambot.Arg{
+			{Name: "user_id", Type: telegrambot.ArgInt},
+			{Name: "role", Type: telegrambot.ArgString, Choices: notification.RoleNames()},
+		},
+		Handler: botRolesHandler.Grant,
+	})
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/revoke",
+		Description: "Revoke the role of a user",
+		Args:        []telegrambot.Arg{{Name: "user_id", Type: telegrambot.ArgInt}},
+		Handler:     botRolesHandler.Revoke,
+	})
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+
+	err = bot.RegisterCommandHandler("/roles",
+		"List the users with a role",
+		botRolesHandler.Roles,
+	)
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+
+	botBroadcastHandler := notification.NewBotBroadcastHandler(ctx, bot, db, _queue, _queue)
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/broadcast",
+		Description: "Send a message, and optionally a photo, to every subscriber",
+		Args:        []telegrambot.Arg{{Name: "message", Type: telegrambot.ArgText}},
+		Handler:     botBroadcastHandler.Broadcast,
+	})
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+	bot.RegisterCallbackHandler(notification.BroadcastCallbackPrefix, botBroadcastHandler.Callback)
+
+	err = bot.RegisterCommandHandler("/outcomes",
+		"Show the availability outcomes statistics",
+		botOutcomeHandler.Outcomes,
+	)
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+
+	botAuditHandler := notification.NewBotAuditHandler(auditLog, cfgStore)
+	err = bot.RegisterCommand(telegrambot.Command{
+		Pattern:     "/audit",
+		Description: "Show the latest audit log entries",
+		Args:        []telegrambot.Arg{{Name: "count", Type: telegrambot.ArgInt, Optional: true}},
+		Handler:     botAuditHandler.Audit,
+	})
+	if err != nil {
+		return dependencies{}, fmt.Errorf("error registering command: %w", err)
+	}
+
+	subscriptionExpiry := notification.NewSubscriptionExpiry(bot, db, cfgStore, languages, auditLog)
+	bot.RegisterCallbackHandler(notification.SubscriptionCallbackPrefix, subscriptionExpiry.Callback)
+
+	queueHandler := notification.NewQueueHandler(ctx, bot, db, archive, _queue, _queue, cfgStore, policy, languages, botBroadcastHandler)
 	err = _queue.Subscribe(notification.NotifierTopicName, queueHandler.NotifyTopic)
 	if err != nil {
 		return dependencies{}, fmt.Errorf("error subscribing to topic '%s': %w",
@@ -130,8 +272,16 @@ func buildDependencies(ctx context.Context, _queue *queue.Queue) (dependencies,
 	}
 
 	deps := dependencies{
-		bot: bot,
-		api: api.NewHandler(db),
+		bot:           bot,
+		api:           api.NewHandler(db, archive, _queue, cfgStore, db),
+		admin:         api.NewAdminHandler(db, cfgStore, db, notification.NewTestSender(bot, languages)),
+		audit:         api.NewAuditHandler(auditLog, cfgStore),
+		archive:       archive,
+		retention:     []*retention.Job{resultsRetention, auditRetention},
+		subscriptions: subscriptionExpiry,
+		maintenance: func(ctx context.Context) {
+			runMaintenance(ctx, db, cfg.DB)
+		},
 		tearDown: func() {
 			slog.Info("tearing down services")
 
@@ -146,3 +296,19 @@ func buildDependencies(ctx context.Context, _queue *queue.Queue) (dependencies,
 
 	return deps, nil
 }
+
+func retentionPolicy(cfg config.Config) screenshot.RetentionPolicy {
+	return screenshot.RetentionPolicy{
+		MaxAge:   cfg.Screenshots.MaxAge,
+		MaxSize:  cfg.Screenshots.MaxSize,
+		Schedule: cfg.Screenshots.RetentionSchedule,
+	}
+}
+
+func resultsRetentionPolicy(cfg config.Config) retention.Policy {
+	return retention.Policy{MaxAge: cfg.Results.MaxAge, Schedule: cfg.Results.PruneSchedule}
+}
+
+func auditRetentionPolicy(cfg config.Config) retention.Policy {
+	return retention.Policy{MaxAge: cfg.Audit.MaxAge, Schedule: cfg.Audit.PruneSchedule}
+}
@@ -3,21 +3,91 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"time"
+	// The subscribers time zones must load in the containers without the tz database.
+	_ "time/tzdata"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/skryde/booking-check/server/internal/config"
 	"github.com/skryde/booking-check/server/internal/platform/queue"
 )
 
+const configWatchInterval = 5 * time.Second
+
+// commands are the subcommands of the server binary, without one it runs the server.
+var commands = map[string]func(args []string) error{
+	"backup":             runBackup,
+	"restore":            runRestore,
+	"export-subscribers": runExportSubscribers,
+	"import-subscribers": runImportSubscribers,
+	"subs":               runSubs,
+	"debug":              runDebug,
+	"send-test":          runSendTest,
+	"results":            runResults,
+	"db":                 runDB,
+	"config":             runConfig,
+}
+
 func main() {
-	// Run NATS Server
-	_queue, err := queue.RunEmbeddedNATS(false, false)
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				slog.Error("failed to run command", slog.String("command", os.Args[1]), slog.Any("error", err))
+				os.Exit(1)
+			}
+
+			return
+		}
+	}
+
+	flags := flag.NewFlagSet("server", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	printConfig := flags.Bool("print-config", false, "print the configuration, with the secrets masked, and exit")
+	_ = flags.Parse(os.Args[1:])
+
+	hangup := config.NotifyHangup()
+
+	cfg, err := loader.Load()
+	if err != nil {
+		slog.Error("failed to load configuration", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		out, err := cfg.Masked().YAML()
+		if err != nil {
+			slog.Error("failed to print configuration", slog.Any("error", err))
+			os.Exit(1)
+		}
+
+		_, _ = os.Stdout.Write(out)
+		return
+	}
+
+	if len(cfg.Telegram.Owners) == 0 {
+		slog.Warn("no bot owners configured, admin commands are disabled")
+	}
+
+	// Run the embedded NATS Server or connect to the external one.
+	_queue, err := queue.New(queue.Config{
+		URL:           cfg.NATS.URL,
+		ListenAddress: cfg.NATS.ListenAddress,
+		StoreDir:      cfg.NATS.StoreDir,
+		User:          cfg.NATS.User,
+		Password:      cfg.NATS.Password,
+		NKeySeedFile:  cfg.NATS.NKeySeedFile,
+		TLSCert:       cfg.NATS.TLSCert,
+		TLSKey:        cfg.NATS.TLSKey,
+		TLSCA:         cfg.NATS.TLSCA,
+	})
 	if err != nil {
-		slog.Error("failed to run embedded NATS server", slog.Any("error", err))
+		slog.Error("failed to start NATS queue", slog.Any("error", err))
 		panic(err)
 	}
 
@@ -27,7 +97,9 @@ func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill)
 	defer cancel()
 
-	deps, err := buildDependencies(ctx, _queue)
+	cfgStore := config.NewStore(loader, cfg)
+
+	deps, err := buildDependencies(ctx, cfgStore, _queue)
 	if err != nil {
 		slog.Error("failed to build dependencies", slog.Any("error", err))
 		os.Exit(1)
@@ -43,11 +115,47 @@ func main() {
 	}
 
 	errGroup.Go(func() error { return deps.bot.Start(ctx) })
+	errGroup.Go(func() error {
+		deps.archive.RunRetention(ctx)
+		return nil
+	})
+	for _, job := range deps.retention {
+		errGroup.Go(func() error {
+			job.Run(ctx)
+			return nil
+		})
+	}
+	errGroup.Go(func() error {
+		deps.subscriptions.Run(ctx)
+		return nil
+	})
+	errGroup.Go(func() error {
+		deps.maintenance(ctx)
+		return nil
+	})
+	errGroup.Go(func() error {
+		cfgStore.Watch(ctx, configWatchInterval, hangup)
+		return nil
+	})
 	errGroup.Go(func() error {
 		mux := &http.ServeMux{}
-		server := &http.Server{Addr: ":8080", Handler: mux}
+		server := &http.Server{Addr: cfg.HTTP.Address, Handler: mux}
 
+		mux.HandleFunc("GET /debug/vars", deps.audit.Audited(deps.api.GetDebugVars))
 		mux.HandleFunc("/subs", deps.api.GetSubscriptions)
+		mux.HandleFunc("POST /screenshots", deps.audit.AuditedScrapper(deps.api.UploadScreenshot))
+		mux.HandleFunc("GET /results", deps.audit.Audited(deps.api.GetResults))
+		mux.HandleFunc("GET /results/{id}/screenshot", deps.audit.Audited(deps.api.GetResultScreenshot))
+		mux.HandleFunc("GET /stats", deps.api.GetStats)
+		mux.HandleFunc("GET /backup", deps.audit.Audited(deps.api.GetBackup))
+		mux.HandleFunc("GET /subscribers/export", deps.audit.Audited(deps.api.ExportSubscribers))
+		mux.HandleFunc("POST /subscribers/import", deps.audit.Audited(deps.api.ImportSubscribers))
+		mux.HandleFunc("POST /admin/subscribers/{chat_id}", deps.audit.Audited(deps.admin.AddSubscriber))
+		mux.HandleFunc("DELETE /admin/subscribers/{chat_id}", deps.audit.Audited(deps.admin.RemoveSubscriber))
+		mux.HandleFunc("PUT /admin/debug", deps.audit.Audited(deps.admin.SetDebug))
+		mux.HandleFunc("POST /admin/send-test/{chat_id}", deps.audit.Audited(deps.admin.SendTest))
+		mux.HandleFunc("POST /admin/db/compact", deps.audit.Audited(deps.admin.Compact))
+		mux.HandleFunc("GET /audit", deps.audit.Audited(deps.audit.GetAudit))
 
 		go onCtxDone(func() {
 			if err := server.Shutdown(ctx); err != nil {
@@ -1,19 +1,27 @@
 package main
 
 import (
+	"context"
+
 	"github.com/skryde/booking-check/server/internal/api"
+	"github.com/skryde/booking-check/server/internal/notification"
 	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/retention"
+	"github.com/skryde/booking-check/server/internal/screenshot"
 )
 
-type configuration struct {
-	dbPath             string
-	telegramBotToken   string
-	telegramBotOwnerID int64
-}
-
 type dependencies struct {
-	bot *telegrambot.TelegramBot
-	api *api.Handler
+	bot     *telegrambot.TelegramBot
+	api     *api.Handler
+	admin   *api.AdminHandler
+	audit   *api.AuditHandler
+	archive *screenshot.Archive
+
+	// retention prunes the results and the audit entries.
+	retention []*retention.Job
+
+	subscriptions *notification.SubscriptionExpiry
+	maintenance   func(ctx context.Context)
 
 	tearDown func()
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/platform/storage/badger"
+	"github.com/skryde/booking-check/server/internal/platform/storage/sqlite"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// storage is the database of the configured driver.
+type storage interface {
+	repository.Repository
+	Backup(w io.Writer, since uint64) (uint64, error)
+	IncrementalBackups() bool
+	Compact() error
+}
+
+func openStorage(cfg config.DB) (storage, error) {
+	// The databases are returned only on success, a nil pointer in the interface would not be nil.
+	switch cfg.Driver {
+	case config.DriverBadger:
+		db, err := badger.NewDB(cfg.Path, badger.Options{LowMemory: cfg.LowMemory})
+		if err != nil {
+			return nil, err
+		}
+
+		return db, nil
+	case config.DriverSQLite:
+		db, err := sqlite.NewDB(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return db, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver '%s'", cfg.Driver)
+	}
+}
+
+// runMaintenance runs the database background maintenance until the context is done, only Badger needs it.
+func runMaintenance(ctx context.Context, db storage, cfg config.DB) {
+	if db, ok := db.(*badger.DB); ok {
+		db.RunMaintenance(ctx, cfg.GCSchedule, cfg.CompactionSchedule)
+	}
+}
+
+// restoreStorage loads the backup into the database of the configured driver.
+func restoreStorage(cfg config.DB, r io.Reader, incremental bool) error {
+	switch cfg.Driver {
+	case config.DriverBadger:
+		return badger.Restore(cfg.Path, r, incremental, badger.Options{LowMemory: cfg.LowMemory})
+	case config.DriverSQLite:
+		return sqlite.Restore(cfg.Path, r, incremental)
+	default:
+		return fmt.Errorf("unsupported database driver '%s'", cfg.Driver)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/subscribers"
+)
+
+// runExportSubscribers writes the subscribers of the configured database, which must not be in use by a running
+// server; use the GET /subscribers/export endpoint for those.
+func runExportSubscribers(args []string) error {
+	flags := flag.NewFlagSet("export-subscribers", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	format := flags.String("format", "json", "export format: json or csv")
+	output := flags.String("output", "", "export file, the standard output if empty")
+	_ = flags.Parse(args)
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	db, err := openStorage(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("error opening database, is the server running? %w", err)
+	}
+	defer db.Close()
+
+	exported, err := subscribers.Export(db)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("error creating export file: %w", err)
+		}
+		defer file.Close()
+
+		w = file
+	}
+
+	if err := subscribers.Encode(w, *format, exported); err != nil {
+		return fmt.Errorf("error encoding subscribers: %w", err)
+	}
+
+	slog.Info("subscribers exported", slog.Int("subscribers", len(exported)))
+
+	return nil
+}
+
+// runImportSubscribers merges the subscribers into the configured database and prints the import report.
+func runImportSubscribers(args []string) error {
+	flags := flag.NewFlagSet("import-subscribers", flag.ExitOnError)
+	loader := config.NewLoader(flags)
+	format := flags.String("format", "json", "import format: json or csv")
+	input := flags.String("input", "", "import file, the standard input if empty")
+	dryRun := flags.Bool("dry-run", false, "report the changes without writing them")
+	_ = flags.Parse(args)
+
+	cfg, err := loader.Load()
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return fmt.Errorf("error opening import file: %w", err)
+		}
+		defer file.Close()
+
+		r = file
+	}
+
+	imported, err := subscribers.Decode(r, *format)
+	if err != nil {
+		return err
+	}
+
+	db, err := openStorage(cfg.DB)
+	if err != nil {
+		return fmt.Errorf("error opening database, is the server running? %w", err)
+	}
+	defer db.Close()
+
+	report, err := subscribers.Import(db, imported, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
@@ -0,0 +1,200 @@
+package access
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// Policy decides which bot commands each user can run based on their role. The configured owners always have
+// the owner role, the rest of the roles are stored in the repository.
+type Policy struct {
+	db     repository.Repository
+	config *config.Store
+
+	mu        sync.RWMutex
+	commands  map[string]repository.Role
+	listeners []func()
+}
+
+func NewPolicy(db repository.Repository, config *config.Store) *Policy {
+	return &Policy{
+		db:       db,
+		config:   config,
+		commands: make(map[string]repository.Role),
+	}
+}
+
+// Require restricts the command, or the buttons with the given callback prefix, to the users with the given role
+// (or a higher one); commands without requirements are allowed to everyone.
+func (p *Policy) Require(command string, role repository.Role) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.commands[command] = role
+}
+
+// Required returns the role required by the command, empty if it's allowed to everyone.
+func (p *Policy) Required(command string) repository.Role {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.commands[command]
+}
+
+// Restricted reports whether the command requires a role, implements telegrambot.Permissions.
+func (p *Policy) Restricted(command string) bool {
+	return p.Required(command) != ""
+}
+
+// Role returns the user role, empty if the user doesn't have one.
+func (p *Policy) Role(userID int64) (repository.Role, error) {
+	if p.config.Current().IsOwner(userID) {
+		return repository.RoleOwner, nil
+	}
+
+	roles, err := p.db.UserRoles()
+	if err != nil {
+		return "", fmt.Errorf("error getting roles: %w", err)
+	}
+
+	return roles[userID], nil
+}
+
+// UserRoles returns the role of each user that has one, including the configured owners.
+func (p *Policy) UserRoles() (map[int64]repository.Role, error) {
+	roles, err := p.db.UserRoles()
+	if err != nil {
+		return nil, fmt.Errorf("error getting roles: %w", err)
+	}
+
+	for _, owner := range p.config.Current().Telegram.Owners {
+		roles[owner] = repository.RoleOwner
+	}
+
+	return roles, nil
+}
+
+// Users returns the users with the given role or a higher one.
+func (p *Policy) Users(role repository.Role) ([]int64, error) {
+	roles, err := p.UserRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	var users []int64
+	for userID, userRole := range roles {
+		if userRole.Includes(role) {
+			users = append(users, userID)
+		}
+	}
+
+	return users, nil
+}
+
+// PrivilegedUsers returns the users with a role, implements telegrambot.Permissions.
+func (p *Policy) PrivilegedUsers() ([]int64, error) {
+	roles, err := p.UserRoles()
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]int64, 0, len(roles))
+	for userID := range roles {
+		users = append(users, userID)
+	}
+
+	return users, nil
+}
+
+// Allowed reports whether the user can run the command, implements telegrambot.Permissions.
+func (p *Policy) Allowed(userID int64, command string) (bool, error) {
+	authorized, _, _, err := p.check(userID, command)
+	return authorized, err
+}
+
+// Authorize implements telegrambot.Authorizer, it logs the unauthorized attempts.
+func (p *Policy) Authorize(_ context.Context, userID int64, command string) (bool, error) {
+	authorized, role, required, err := p.check(userID, command)
+	if err != nil {
+		return false, err
+	}
+
+	if !authorized {
+		slog.Warn("command not authorized",
+			slog.String("audit", "authorization"),
+			slog.Int64("user_id", userID),
+			slog.String("command", command),
+			slog.String("role", string(role)),
+			slog.String("required_role", string(required)),
+		)
+	}
+
+	return authorized, nil
+}
+
+// check returns whether the user is authorized to run the command, along with the user role and the role
+// required by the command.
+func (p *Policy) check(userID int64, command string) (bool, repository.Role, repository.Role, error) {
+	required := p.Required(command)
+	if required == "" {
+		return true, "", "", nil
+	}
+
+	role, err := p.Role(userID)
+	if err != nil {
+		return false, "", required, err
+	}
+
+	return role.Includes(required), role, required, nil
+}
+
+// ConfiguredOwners returns the owners set in the configuration, whose role can't be revoked.
+func (p *Policy) ConfiguredOwners() []int64 {
+	return p.config.Current().Telegram.Owners
+}
+
+// Grant sets the user role.
+func (p *Policy) Grant(userID int64, role repository.Role) error {
+	err := p.db.SetRole(userID, role)
+	if err != nil {
+		return fmt.Errorf("error setting role: %w", err)
+	}
+
+	p.notify()
+	return nil
+}
+
+// Revoke removes the user role.
+func (p *Policy) Revoke(userID int64) error {
+	err := p.db.RemoveRole(userID)
+	if err != nil {
+		return fmt.Errorf("error removing role: %w", err)
+	}
+
+	p.notify()
+	return nil
+}
+
+// OnChange registers a function called after a role is granted or revoked.
+func (p *Policy) OnChange(fn func()) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.listeners = append(p.listeners, fn)
+}
+
+func (p *Policy) notify() {
+	p.mu.RLock()
+	listeners := slices.Clone(p.listeners)
+	p.mu.RUnlock()
+
+	for _, fn := range listeners {
+		fn()
+	}
+}
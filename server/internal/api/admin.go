@@ -0,0 +1,117 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// Compactor reclaims the database disk space.
+type Compactor interface {
+	Compact() error
+}
+
+// TestSender sends a test message to a chat.
+type TestSender interface {
+	SendTest(ctx context.Context, chatID int64) error
+}
+
+// AdminHandler serves the owner-only endpoints used by the admin subcommands against a running server.
+type AdminHandler struct {
+	db        repository.Repository
+	config    *config.Store
+	compactor Compactor
+	sender    TestSender
+}
+
+func NewAdminHandler(db repository.Repository, config *config.Store, compactor Compactor, sender TestSender) *AdminHandler {
+	return &AdminHandler{db: db, config: config, compactor: compactor, sender: sender}
+}
+
+func (h AdminHandler) AddSubscriber(w http.ResponseWriter, r *http.Request) {
+	h.withChatID(w, r, "error adding subscriber", h.db.AddSubscriber)
+}
+
+func (h AdminHandler) RemoveSubscriber(w http.ResponseWriter, r *http.Request) {
+	h.withChatID(w, r, "error removing subscriber", h.db.RemoveSubscriber)
+}
+
+func (h AdminHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	h.withChatID(w, r, "error sending test message", func(chatID int64) error {
+		return h.sender.SendTest(r.Context(), chatID)
+	})
+}
+
+// SetDebug expects the "enabled" query parameter.
+func (h AdminHandler) SetDebug(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	enabled, err := strconv.ParseBool(r.URL.Query().Get("enabled"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"invalid enabled"}`))
+		return
+	}
+
+	if err := h.db.ManageDebug(enabled); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error managing debug", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write([]byte(`{"status": 200, "message":"ok"}`))
+}
+
+func (h AdminHandler) Compact(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := h.compactor.Compact(); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error compacting database", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write([]byte(`{"status": 200, "message":"ok"}`))
+}
+
+// withChatID runs the action with the "chat_id" path value.
+func (h AdminHandler) withChatID(w http.ResponseWriter, r *http.Request, errorMessage string, action func(int64) error) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	chatID, err := strconv.ParseInt(r.PathValue("chat_id"), 10, 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"invalid chat ID"}`))
+		return
+	}
+
+	if err := action(chatID); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error(errorMessage, slog.Int64("chat_id", chatID), slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write([]byte(`{"status": 200, "message":"ok"}`))
+}
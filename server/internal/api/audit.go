@@ -0,0 +1,152 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the audit log and records the authenticated endpoints calls in it.
+type AuditHandler struct {
+	log    *audit.Log
+	config *config.Store
+}
+
+func NewAuditHandler(log *audit.Log, config *config.Store) *AuditHandler {
+	return &AuditHandler{log: log, config: config}
+}
+
+// GetAudit returns the latest audit entries, newest first. They can be filtered by the "actor", "action" and
+// "since" (RFC 3339) query parameters, and "limit" sets how many are returned.
+func (h AuditHandler) GetAudit(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	filter := repository.AuditFilter{
+		Actor:  query.Get("actor"),
+		Action: query.Get("action"),
+		Limit:  defaultAuditLimit,
+	}
+
+	if value := query.Get("since"); value != "" {
+		var err error
+		filter.Since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid since"}`))
+			return
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		var err error
+		filter.Limit, err = strconv.Atoi(value)
+		if err != nil || filter.Limit <= 0 || filter.Limit > maxAuditLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid limit"}`))
+			return
+		}
+	}
+
+	entries, err := h.log.Entries(filter)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error getting audit entries", slog.Any("error", err))
+		return
+	}
+
+	response, err := json.Marshal(entries)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error marshalling response", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write(response)
+}
+
+// Audited records the calls to the owner-only handler, the action is the route pattern and the target the
+// "chat_id" or "id" path value or, without them, the query string. The requests with a wrong token are recorded as
+// denied, the rest of the error statuses as failed. Nothing is recorded while the owner endpoints are disabled.
+func (h AuditHandler) Audited(next http.HandlerFunc) http.HandlerFunc {
+	ownerToken := func(cfg config.Config) string { return cfg.HTTP.OwnerToken }
+	return h.audited(repository.AuditActorAPI, ownerToken, next)
+}
+
+// AuditedScrapper records the calls to the scrapper handler like Audited does, while the scrapper endpoints are
+// enabled.
+func (h AuditHandler) AuditedScrapper(next http.HandlerFunc) http.HandlerFunc {
+	scrapperToken := func(cfg config.Config) string { return cfg.HTTP.ScrapperToken }
+	return h.audited(repository.AuditActorScrapper, scrapperToken, next)
+}
+
+// audited records the calls to the handler as the given actor while the token of its endpoints is set.
+func (h AuditHandler) audited(
+	actor string,
+	token func(config.Config) string,
+	next http.HandlerFunc,
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token(h.config.Current()) == "" {
+			next(w, r)
+			return
+		}
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(recorder, r)
+
+		target := r.PathValue("chat_id")
+		if target == "" {
+			target = r.PathValue("id")
+		}
+
+		if target == "" {
+			target = r.URL.RawQuery
+		}
+
+		switch status := recorder.status; {
+		case status == http.StatusUnauthorized:
+			h.log.RecordDenied(actor, r.Pattern, target, "unauthorized")
+		case status >= http.StatusBadRequest:
+			h.log.Record(actor, r.Pattern, target, fmt.Errorf("status %d", status))
+		default:
+			h.log.Record(actor, r.Pattern, target, nil)
+		}
+	}
+}
+
+// statusRecorder keeps the response status.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the original writer, e.g. to flush the backups.
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestAudited(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTP.OwnerToken = "owner"
+	cfgStore := config.NewStore(nil, cfg)
+
+	db := memory.NewDB()
+	h := NewAuditHandler(audit.NewLog(db), cfgStore)
+
+	owner := func(w http.ResponseWriter, r *http.Request) { authorizeOwner(cfgStore, w, r) }
+	scrapper := func(w http.ResponseWriter, r *http.Request) { authorizeScrapper(cfgStore, w, r) }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /results/{id}/screenshot", h.Audited(owner))
+	mux.HandleFunc("GET /audit", h.Audited(owner))
+	mux.HandleFunc("POST /screenshots", h.AuditedScrapper(scrapper))
+
+	requests := []struct {
+		method, path, token string
+	}{
+		{http.MethodGet, "/results/7/screenshot", "owner"},
+		{http.MethodGet, "/audit?actor=api", "wrong"},
+		// The scrapper endpoints are disabled, the call isn't recorded.
+		{http.MethodPost, "/screenshots", "scrapper"},
+	}
+
+	for _, req := range requests {
+		r := httptest.NewRequest(req.method, req.path, nil)
+		r.Header.Set("Authorization", "Bearer "+req.token)
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	entries, err := db.AuditEntries(repository.AuditFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []repository.AuditEntry{
+		{Actor: repository.AuditActorAPI, Action: "GET /audit", Target: "actor=api", Outcome: repository.AuditDenied},
+		{Actor: repository.AuditActorAPI, Action: "GET /results/{id}/screenshot", Target: "7", Outcome: repository.AuditSucceeded},
+	}
+
+	if len(entries) != len(want) {
+		t.Fatalf("entries = %+v, want %+v", entries, want)
+	}
+
+	for i, entry := range entries {
+		if entry.Actor != want[i].Actor || entry.Action != want[i].Action || entry.Target != want[i].Target ||
+			entry.Outcome != want[i].Outcome {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+
+	cfg.HTTP.ScrapperToken = "scrapper"
+	cfgStore = config.NewStore(nil, cfg)
+	h = NewAuditHandler(audit.NewLog(db), cfgStore)
+
+	r := httptest.NewRequest(http.MethodPost, "/screenshots", nil)
+	r.Header.Set("Authorization", "Bearer scrapper")
+	w := httptest.NewRecorder()
+	h.AuditedScrapper(scrapper)(w, r)
+
+	entries, err = db.AuditEntries(repository.AuditFilter{Actor: repository.AuditActorScrapper})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Outcome != repository.AuditSucceeded {
+		t.Errorf("scrapper entries = %+v, want the upload", entries)
+	}
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/skryde/booking-check/server/internal/config"
+)
+
+// Backuper writes the database backups.
+type Backuper interface {
+	// Backup writes the entries changed after the since version and returns the next incremental backup one.
+	Backup(w io.Writer, since uint64) (uint64, error)
+	// IncrementalBackups reports whether Backup supports since versions other than 0.
+	IncrementalBackups() bool
+}
+
+// GetBackup streams a database backup, incremental since the "since" query parameter version if given. The backup
+// SHA-256 and the since version of the next incremental backup, to be passed as is, are sent as the
+// X-Backup-Sha256 and X-Backup-Version trailers. It requires the owner token.
+func (h Handler) GetBackup(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	var since uint64
+	if value := r.URL.Query().Get("since"); value != "" {
+		var err error
+		since, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid since version"}`))
+			return
+		}
+	}
+
+	// Once the backup starts streaming, the status can't tell the client it failed.
+	if since != 0 && !h.backups.IncrementalBackups() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"the database only supports full backups"}`))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="booking-check.backup"`)
+	w.Header().Set("Trailer", "X-Backup-Sha256, X-Backup-Version")
+
+	hash := sha256.New()
+	version, err := h.backups.Backup(io.MultiWriter(w, hash), since)
+	if err != nil {
+		// The status was already sent, the missing trailers tell the client the backup is incomplete.
+		slog.Error("error streaming backup", slog.Any("error", err))
+		return
+	}
+
+	w.Header().Set("X-Backup-Sha256", hex.EncodeToString(hash.Sum(nil)))
+	w.Header().Set("X-Backup-Version", strconv.FormatUint(version, 10))
+
+	slog.Info("backup streamed",
+		slog.Uint64("since", since),
+		slog.Uint64("version", version),
+		slog.String("remote_address", r.RemoteAddr),
+	)
+}
+
+// authorizeOwner checks the request bearer token against the owner one, replying the error if it doesn't match.
+func authorizeOwner(config *config.Store, w http.ResponseWriter, r *http.Request) bool {
+	return authorizeToken(config.Current().HTTP.OwnerToken, "owner", w, r)
+}
+
+// authorizeScrapper checks the request bearer token against the scrapper one, replying the error if it doesn't
+// match.
+func authorizeScrapper(config *config.Store, w http.ResponseWriter, r *http.Request) bool {
+	return authorizeToken(config.Current().HTTP.ScrapperToken, "scrapper", w, r)
+}
+
+// authorizeToken checks the request bearer token against the given one, the endpoints of the kind are disabled
+// when it's empty.
+func authorizeToken(token, kind string, w http.ResponseWriter, r *http.Request) bool {
+	if token == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = fmt.Fprintf(w, `{"status": 404, "message":"%s endpoints are disabled"}`, kind)
+		return false
+	}
+
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		slog.Warn("unauthorized "+kind+" request",
+			slog.String("path", r.URL.Path),
+			slog.String("remote_address", r.RemoteAddr),
+		)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"status": 401, "message":"unauthorized"}`))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/config"
+)
+
+// fullBackuper writes "backup" as the backup, the way a database without incremental backups does.
+type fullBackuper struct{}
+
+func (fullBackuper) Backup(w io.Writer, since uint64) (uint64, error) {
+	_, err := io.WriteString(w, "backup")
+	return 0, err
+}
+
+func (fullBackuper) IncrementalBackups() bool {
+	return false
+}
+
+func TestGetBackup(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTP.OwnerToken = "owner"
+	h := Handler{config: config.NewStore(nil, cfg), backups: fullBackuper{}}
+
+	tests := []struct {
+		name     string
+		query    string
+		want     int
+		wantBody string
+	}{
+		{"full", "", http.StatusOK, "backup"},
+		{"since 0", "?since=0", http.StatusOK, "backup"},
+		{"incremental", "?since=10", http.StatusBadRequest, `{"status": 400, "message":"the database only supports full backups"}`},
+		{"invalid since", "?since=last", http.StatusBadRequest, `{"status": 400, "message":"invalid since version"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/backup"+tt.query, nil)
+			r.Header.Set("Authorization", "Bearer owner")
+			w := httptest.NewRecorder()
+
+			h.GetBackup(w, r)
+
+			if w.Code != tt.want || w.Body.String() != tt.wantBody {
+				t.Errorf("response = %d %q, want %d %q", w.Code, w.Body.String(), tt.want, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// clientTimeout bounds the admin API requests, the database compaction may take a while.
+const clientTimeout = 5 * time.Minute
+
+// Client calls the admin API of a running server, authenticated with the owner token.
+type Client struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func NewClient(baseURL, token string) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		http:    &http.Client{Timeout: clientTimeout},
+	}
+}
+
+func (c *Client) Subscribers() ([]int64, error) {
+	var subs []int64
+	return subs, c.do(context.Background(), http.MethodGet, "/subs", &subs)
+}
+
+func (c *Client) AddSubscriber(id int64) error {
+	return c.do(context.Background(), http.MethodPost, "/admin/subscribers/"+strconv.FormatInt(id, 10), nil)
+}
+
+func (c *Client) RemoveSubscriber(id int64) error {
+	return c.do(context.Background(), http.MethodDelete, "/admin/subscribers/"+strconv.FormatInt(id, 10), nil)
+}
+
+func (c *Client) ManageDebug(enable bool) error {
+	return c.do(context.Background(), http.MethodPut, "/admin/debug?enabled="+strconv.FormatBool(enable), nil)
+}
+
+func (c *Client) Results(limit int) ([]repository.Result, error) {
+	var results []repository.Result
+	return results, c.do(context.Background(), http.MethodGet, "/results?limit="+strconv.Itoa(limit), &results)
+}
+
+func (c *Client) SendTest(ctx context.Context, chatID int64) error {
+	return c.do(ctx, http.MethodPost, "/admin/send-test/"+strconv.FormatInt(chatID, 10), nil)
+}
+
+func (c *Client) Compact() error {
+	return c.do(context.Background(), http.MethodPost, "/admin/db/compact", nil)
+}
+
+// Close does nothing, it's there so the client can replace the offline database.
+func (c *Client) Close() error {
+	return nil
+}
+
+// do sends the request and decodes the JSON response into v, if not nil.
+func (c *Client) do(ctx context.Context, method, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading %s %s response: %w", method, path, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(body, &apiErr)
+
+		return fmt.Errorf("%s %s failed with status %d: %s", method, path, resp.StatusCode, apiErr.Message)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("error decoding %s %s response: %w", method, path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+)
+
+// GetDebugVars serves the expvar variables, only for the owners: they include the command line, which may have
+// secrets such as the bot token.
+func (h Handler) GetDebugVars(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	expvar.Handler().ServeHTTP(w, r)
+}
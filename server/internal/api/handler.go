@@ -1,19 +1,37 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"log/slog"
 	"net/http"
 
+	"github.com/skryde/booking-check/server/internal/config"
 	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/screenshot"
 )
 
+// ObjectStore holds the payloads too big to travel inside the queue messages.
+type ObjectStore interface {
+	PutObject(ctx context.Context, name string, data []byte) error
+}
+
 type Handler struct {
-	db repository.Repository
+	db      repository.Repository
+	archive *screenshot.Archive
+	objects ObjectStore
+	config  *config.Store
+	backups Backuper
 }
 
-func NewHandler(db repository.Repository) *Handler {
-	return &Handler{db: db}
+func NewHandler(
+	db repository.Repository,
+	archive *screenshot.Archive,
+	objects ObjectStore,
+	config *config.Store,
+	backups Backuper,
+) *Handler {
+	return &Handler{db: db, archive: archive, objects: objects, config: config, backups: backups}
 }
 
 func (h Handler) GetSubscriptions(w http.ResponseWriter, r *http.Request) {
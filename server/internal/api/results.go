@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+const (
+	defaultResultsLimit = 50
+	maxResultsLimit     = 1000
+)
+
+// GetResults returns the latest results, newest first, the "limit" query parameter sets how many. Only for the
+// owners, the results include the debug messages.
+func (h Handler) GetResults(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	limit := defaultResultsLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 || limit > maxResultsLimit {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid limit"}`))
+			return
+		}
+	}
+
+	results, err := h.db.Results(limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error getting results", slog.Any("error", err))
+		return
+	}
+
+	response, err := json.Marshal(results)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error marshalling response", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write(response)
+}
+
+// GetResultScreenshot returns the screenshot archived with the result, only for the owners.
+func (h Handler) GetResultScreenshot(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"invalid result ID"}`))
+		return
+	}
+
+	result, err := h.db.Result(id)
+	if err == nil && result.ScreenshotID == "" {
+		err = repository.ErrNotFound
+	}
+
+	var image []byte
+	if err == nil {
+		image, err = h.archive.Load(result.ScreenshotID)
+	}
+
+	if errors.Is(err, repository.ErrNotFound) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"status": 404, "message":"screenshot not found"}`))
+		return
+	}
+
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error getting result screenshot",
+			slog.Uint64("result_id", id),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	w.Header().Set("Content-Type", http.DetectContentType(image))
+	_, _ = w.Write(image)
+}
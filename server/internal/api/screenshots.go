@@ -0,0 +1,63 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+)
+
+// maxScreenshotSize is the biggest screenshot accepted by the upload endpoint, the Telegram photos limit. The
+// object store bucket limits the total size.
+const maxScreenshotSize = 10 << 20 // 10 MiB
+
+// UploadScreenshot stores the request body in the object store and returns the reference the scrapper should
+// send in the result 'image_ref' field. Only for the scrapper.
+func (h Handler) UploadScreenshot(w http.ResponseWriter, r *http.Request) {
+	if !authorizeScrapper(h.config, w, r) {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	image, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxScreenshotSize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = w.Write([]byte(`{"status": 413, "message":"screenshot too large"}`))
+		return
+	}
+
+	if err != nil || len(image) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"invalid screenshot"}`))
+		return
+	}
+
+	sum := sha256.Sum256(image)
+	imageRef := hex.EncodeToString(sum[:])
+
+	err = h.objects.PutObject(r.Context(), imageRef, image)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error putting screenshot in object store", slog.Any("error", err))
+		return
+	}
+
+	response, err := json.Marshal(map[string]string{"image_ref": imageRef})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error marshalling response", slog.Any("error", err))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(response)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/stats"
+)
+
+// maxStatsDays is the longest period of the statistics, in days.
+const maxStatsDays = 366
+
+// GetStats returns the availability statistics of the last 30 days, or of the "days" query parameter ones, up to
+// maxStatsDays.
+func (h Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	period := stats.DefaultPeriod
+	if value := r.URL.Query().Get("days"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days <= 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid days"}`))
+			return
+		}
+
+		if days > maxStatsDays {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, `{"status": 400, "message":"days can't be more than %d"}`, maxStatsDays)
+			return
+		}
+
+		period = time.Duration(days) * 24 * time.Hour
+	}
+
+	location, err := time.LoadLocation(h.config.Current().Telegram.DefaultTimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	now := time.Now()
+	since := now.Add(-period)
+
+	results, err := h.db.ResultsSince(since)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error getting results", slog.Any("error", err))
+		return
+	}
+
+	response, err := json.Marshal(stats.Compute(results, since, now, location))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error marshalling response", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write(response)
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+)
+
+func TestGetStats(t *testing.T) {
+	h := Handler{config: config.NewStore(nil, config.Default()), db: memory.NewDB()}
+
+	tests := []struct {
+		name     string
+		query    string
+		want     int
+		wantBody string
+	}{
+		{"default period", "", http.StatusOK, `{"since":`},
+		{"longest period", "?days=366", http.StatusOK, `{"since":`},
+		{"too long period", "?days=367", http.StatusBadRequest, `{"status": 400, "message":"days can't be more than 366"}`},
+		{"overflowing period", "?days=200000", http.StatusBadRequest, `{"status": 400, "message":"days can't be more than 366"}`},
+		{"invalid days", "?days=0", http.StatusBadRequest, `{"status": 400, "message":"invalid days"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetStats(w, httptest.NewRequest(http.MethodGet, "/stats"+tt.query, nil))
+
+			if body := w.Body.String(); w.Code != tt.want || !strings.HasPrefix(body, tt.wantBody) {
+				t.Errorf("response = %d %q, want %d %q", w.Code, body, tt.want, tt.wantBody)
+			}
+		})
+	}
+}
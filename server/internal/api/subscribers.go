@@ -0,0 +1,135 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/skryde/booking-check/server/internal/subscribers"
+)
+
+// maxImportSize is the biggest subscribers file accepted by the import endpoint.
+const maxImportSize = 16 << 20
+
+// ExportSubscribers returns the subscribers, with their settings, in the "format" query parameter one: json (the
+// default) or csv. It requires the owner token.
+func (h Handler) ExportSubscribers(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	format, ok := subscribersFormat(w, r)
+	if !ok {
+		return
+	}
+
+	exported, err := subscribers.Export(h.db)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error exporting subscribers", slog.Any("error", err))
+		return
+	}
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="subscribers.%s"`, format))
+	if err := subscribers.Encode(w, format, exported); err != nil {
+		slog.Error("error encoding subscribers", slog.Any("error", err))
+	}
+}
+
+// ImportSubscribers merges the subscribers in the body, in the "format" query parameter one, and returns the import
+// report. Nothing is written when the "dry_run" query parameter is true. It requires the owner token.
+func (h Handler) ImportSubscribers(w http.ResponseWriter, r *http.Request) {
+	if !authorizeOwner(h.config, w, r) {
+		return
+	}
+
+	format, ok := subscribersFormat(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var dryRun bool
+	if value := r.URL.Query().Get("dry_run"); value != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"status": 400, "message":"invalid dry_run"}`))
+			return
+		}
+	}
+
+	imported, err := subscribers.Decode(http.MaxBytesReader(w, r.Body, maxImportSize), format)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		response, _ := json.Marshal(map[string]any{"status": http.StatusBadRequest, "message": err.Error()})
+		_, _ = w.Write(response)
+		return
+	}
+
+	report, err := subscribers.Import(h.db, imported, dryRun)
+	if errors.Is(err, subscribers.ErrInvalid) {
+		w.WriteHeader(http.StatusBadRequest)
+		response, _ := json.Marshal(map[string]any{"status": http.StatusBadRequest, "message": err.Error()})
+		_, _ = w.Write(response)
+		return
+	}
+
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error importing subscribers", slog.Any("error", err))
+		return
+	}
+
+	slog.Info("subscribers imported",
+		slog.Bool("dry_run", report.DryRun),
+		slog.Int("added", report.Added),
+		slog.Int("updated", report.Updated),
+		slog.Int("unchanged", report.Unchanged),
+		slog.Int("conflicts", len(report.Conflicts)),
+	)
+
+	response, err := json.Marshal(report)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`{"status": 500, "message":"internal error"}`))
+
+		slog.Error("error marshalling response", slog.Any("error", err))
+		return
+	}
+
+	_, _ = w.Write(response)
+}
+
+func subscribersFormat(w http.ResponseWriter, r *http.Request) (string, bool) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		return "json", true
+	}
+
+	if !slices.Contains(subscribers.Formats, format) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status": 400, "message":"invalid format"}`))
+		return "", false
+	}
+
+	return format, true
+}
@@ -0,0 +1,147 @@
+// Package audit records the admin and subscription actions in the audit log of the repository.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+const (
+	// maxTargetLength limits the recorded targets, the command arguments may be long texts (e.g. /broadcast).
+	maxTargetLength = 200
+
+	// deniedPerMinute and deniedBurst limit the denied entries recorded, anyone can be denied (e.g. calling the
+	// API with a wrong token) and they would grow the log without limit otherwise.
+	deniedPerMinute = 10
+	deniedBurst     = 20
+)
+
+// Log records the audit entries. The errors storing them are logged, they must not fail the audited actions.
+type Log struct {
+	db repository.Repository
+
+	// denied limits the denied entries, dropped counts the ones over the limit until the next one is recorded.
+	mu      sync.Mutex
+	denied  *rate.Limiter
+	dropped int
+}
+
+func NewLog(db repository.Repository) *Log {
+	return &Log{
+		db:     db,
+		denied: rate.NewLimiter(rate.Every(time.Minute/deniedPerMinute), deniedBurst),
+	}
+}
+
+// Record stores the action run by the actor on the target, which ended with err; a nil one means it succeeded.
+func (l *Log) Record(actor, action, target string, err error) {
+	entry := repository.AuditEntry{
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Outcome: repository.AuditSucceeded,
+	}
+
+	if err != nil {
+		entry.Outcome = repository.AuditFailed
+		entry.Error = err.Error()
+	}
+
+	l.add(slog.Default(), entry)
+}
+
+// RecordDenied stores the action the actor was not allowed to run on the target.
+func (l *Log) RecordDenied(actor, action, target, reason string) {
+	l.add(slog.Default(), repository.AuditEntry{
+		Actor:   actor,
+		Action:  action,
+		Target:  target,
+		Outcome: repository.AuditDenied,
+		Error:   reason,
+	})
+}
+
+// RecordCommand stores the bot command, its target is the command arguments or, for the commands without them,
+// the chat ID.
+func (l *Log) RecordCommand(ctx context.Context, record telegrambot.CommandRecord) {
+	entry := repository.AuditEntry{
+		Actor:   repository.TelegramActor(record.UserID),
+		Action:  record.Command,
+		Target:  record.Args,
+		Outcome: repository.AuditSucceeded,
+	}
+
+	if entry.Target == "" {
+		entry.Target = strconv.FormatInt(record.ChatID, 10)
+	}
+
+	switch {
+	case record.Denied:
+		entry.Outcome = repository.AuditDenied
+	case record.Err != nil:
+		entry.Outcome = repository.AuditFailed
+	}
+
+	if record.Err != nil {
+		entry.Error = record.Err.Error()
+	}
+
+	l.add(telegrambot.Logger(ctx), entry)
+}
+
+// Entries returns the entries selected by the filter, newest first.
+func (l *Log) Entries(filter repository.AuditFilter) ([]repository.AuditEntry, error) {
+	return l.db.AuditEntries(filter)
+}
+
+func (l *Log) add(logger *slog.Logger, entry repository.AuditEntry) {
+	if entry.Outcome == repository.AuditDenied && !l.allowDenied(&entry) {
+		logger.Debug("denied audit entry dropped",
+			slog.String("actor", entry.Actor),
+			slog.String("action", entry.Action),
+		)
+		return
+	}
+
+	if runes := []rune(entry.Target); len(runes) > maxTargetLength {
+		entry.Target = string(runes[:maxTargetLength]) + "…"
+	}
+
+	entry.CreatedAt = time.Now()
+
+	if _, err := l.db.AddAuditEntry(entry); err != nil {
+		logger.Error("error recording audit entry",
+			slog.String("actor", entry.Actor),
+			slog.String("action", entry.Action),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// allowDenied reports whether the denied entry is under the rate limit, adding to its error how many were dropped
+// since the previous one.
+func (l *Log) allowDenied(entry *repository.AuditEntry) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.denied.Allow() {
+		l.dropped++
+		return false
+	}
+
+	if l.dropped > 0 {
+		entry.Error = fmt.Sprintf("%s (%d more denied entries dropped before this one)", entry.Error, l.dropped)
+		l.dropped = 0
+	}
+
+	return true
+}
@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestRecordDenied(t *testing.T) {
+	db := memory.NewDB()
+	log := NewLog(db)
+
+	// The denied entries over the burst are dropped, the rest are always recorded.
+	for range deniedBurst + 5 {
+		log.RecordDenied(repository.AuditActorAPI, "GET /backup", "", "unauthorized")
+	}
+
+	log.Record(repository.AuditActorAPI, "GET /backup", "", nil)
+
+	denied, err := db.AuditEntries(repository.AuditFilter{Actor: repository.AuditActorAPI})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(denied) != deniedBurst+1 {
+		t.Fatalf("got %d audit entries, want %d", len(denied), deniedBurst+1)
+	}
+
+	if denied[0].Outcome != repository.AuditSucceeded {
+		t.Errorf("latest entry outcome = %q, want %q", denied[0].Outcome, repository.AuditSucceeded)
+	}
+
+	// Once the limit allows it again, the next denied entry tells how many were dropped.
+	log.denied.SetBurst(1)
+	log.denied.SetLimit(1e9)
+	log.RecordDenied(repository.AuditActorAPI, "GET /backup", "", "unauthorized")
+
+	entries, err := db.AuditEntries(repository.AuditFilter{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := "unauthorized (5 more denied entries dropped before this one)"; len(entries) != 1 || entries[0].Error != want {
+		t.Errorf("entries = %+v, want the error %q", entries, want)
+	}
+
+	if strings.Contains(denied[1].Error, "dropped") {
+		t.Errorf("entry error = %q, want nothing dropped before it", denied[1].Error)
+	}
+}
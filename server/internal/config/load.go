@@ -0,0 +1,226 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// setting is a configuration value that can be set through an environment variable and a command line flag.
+// The boolean settings flags can be given without a value.
+type setting struct {
+	env     string
+	flag    string
+	usage   string
+	set     func(cfg *Config, value string) error
+	boolean bool
+}
+
+var settings = []setting{
+	{"DB_DRIVER", "db-driver", "database driver: badger or sqlite", setString(func(c *Config) *string { return &c.DB.Driver }), false},
+	{"DB_PATH", "db-path", "Badger database directory or SQLite database file", setString(func(c *Config) *string { return &c.DB.Path }), false},
+	{"DB_GC_SCHEDULE", "db-gc-schedule", "Badger value log garbage collection schedule", setDuration(func(c *Config) *time.Duration { return &c.DB.GCSchedule }), false},
+	{"DB_COMPACTION_SCHEDULE", "db-compaction-schedule", "Badger LSM tree compaction schedule, 0 disables it", setDuration(func(c *Config) *time.Duration { return &c.DB.CompactionSchedule }), false},
+	{"DB_LOW_MEMORY", "db-low-memory", "tune Badger for hosts with little memory", setBool(func(c *Config) *bool { return &c.DB.LowMemory }), true},
+	{"HTTP_ADDRESS", "http-address", "HTTP API listen address", setString(func(c *Config) *string { return &c.HTTP.Address }), false},
+	{"HTTP_OWNER_TOKEN", "http-owner-token", "bearer token of the owner-only HTTP endpoints", setString(func(c *Config) *string { return &c.HTTP.OwnerToken }), false},
+	{"HTTP_SCRAPPER_TOKEN", "http-scrapper-token", "bearer token of the scrapper screenshot uploads", setString(func(c *Config) *string { return &c.HTTP.ScrapperToken }), false},
+	{"NATS_URL", "nats-url", "external NATS server URL", setString(func(c *Config) *string { return &c.NATS.URL }), false},
+	{"NATS_LISTEN_ADDRESS", "nats-listen-address", "embedded NATS server listen address", setString(func(c *Config) *string { return &c.NATS.ListenAddress }), false},
+	{"NATS_STORE_DIR", "nats-store-dir", "embedded NATS server JetStream directory", setString(func(c *Config) *string { return &c.NATS.StoreDir }), false},
+	{"NATS_USER", "nats-user", "NATS user", setString(func(c *Config) *string { return &c.NATS.User }), false},
+	{"NATS_PASSWORD", "nats-password", "NATS password", setString(func(c *Config) *string { return &c.NATS.Password }), false},
+	{"NATS_NKEY_SEED_FILE", "nats-nkey-seed-file", "NATS NKey seed file", setString(func(c *Config) *string { return &c.NATS.NKeySeedFile }), false},
+	{"NATS_TLS_CERT", "nats-tls-cert", "NATS TLS certificate file", setString(func(c *Config) *string { return &c.NATS.TLSCert }), false},
+	{"NATS_TLS_KEY", "nats-tls-key", "NATS TLS key file", setString(func(c *Config) *string { return &c.NATS.TLSKey }), false},
+	{"NATS_TLS_CA", "nats-tls-ca", "NATS TLS CA certificate file", setString(func(c *Config) *string { return &c.NATS.TLSCA }), false},
+	{"TELEGRAM_BOT_TOKEN", "telegram-bot-token", "Telegram Bot API token", setString(func(c *Config) *string { return &c.Telegram.BotToken }), false},
+	{"TELEGRAM_BOT_OWNER_ID", "telegram-bot-owner-id", "comma separated Telegram owner IDs", setOwners, false},
+	{"TELEGRAM_DEFAULT_LANGUAGE", "telegram-default-language", "language of the users whose Telegram language is not supported", setString(func(c *Config) *string { return &c.Telegram.DefaultLanguage }), false},
+	{"TELEGRAM_DEFAULT_TIME_ZONE", "telegram-default-time-zone", "time zone of the subscribers quiet hours", setString(func(c *Config) *string { return &c.Telegram.DefaultTimeZone }), false},
+	{"RATE_LIMIT_MESSAGES_PER_SECOND", "rate-limit-messages-per-second", "messages sent per second by the bot", setMessagesPerSecond, false},
+	{"RATE_LIMIT_BURST", "rate-limit-burst", "messages burst allowed to the bot", setBurst, false},
+	{"RESULT_MAX_AGE", "result-max-age", "stored results max age, 0 to keep them", setDuration(func(c *Config) *time.Duration { return &c.Results.MaxAge }), false},
+	{"RESULT_PRUNE_SCHEDULE", "result-prune-schedule", "stored results pruning schedule", setDuration(func(c *Config) *time.Duration { return &c.Results.PruneSchedule }), false},
+	{"AUDIT_MAX_AGE", "audit-max-age", "audit entries max age, 0 to keep them", setDuration(func(c *Config) *time.Duration { return &c.Audit.MaxAge }), false},
+	{"AUDIT_PRUNE_SCHEDULE", "audit-prune-schedule", "audit entries pruning schedule", setDuration(func(c *Config) *time.Duration { return &c.Audit.PruneSchedule }), false},
+	{"SCREENSHOT_MAX_AGE", "screenshot-max-age", "archived screenshots max age", setDuration(func(c *Config) *time.Duration { return &c.Screenshots.MaxAge }), false},
+	{"SCREENSHOT_MAX_SIZE", "screenshot-max-size", "archived screenshots max total size in bytes", setScreenshotMaxSize, false},
+	{"SUBSCRIPTION_TTL", "subscription-ttl", "time after which the subscribers are asked to confirm their subscription, 0 to disable it", setDuration(func(c *Config) *time.Duration { return &c.Subscriptions.TTL }), false},
+}
+
+// Loader builds the configuration from, in increasing precedence order: the defaults, the configuration file,
+// the environment variables and the command line flags.
+type Loader struct {
+	flags      *flag.FlagSet
+	configFile *string
+	values     map[string]*string
+}
+
+// NewLoader registers the configuration flags in the given flag set, which must be parsed before calling Load.
+func NewLoader(flags *flag.FlagSet) *Loader {
+	l := &Loader{
+		flags:      flags,
+		configFile: flags.String("config", "", "YAML configuration file (env CONFIG_FILE)"),
+		values:     make(map[string]*string, len(settings)),
+	}
+
+	for _, s := range settings {
+		usage := fmt.Sprintf("%s (env %s)", s.usage, s.env)
+		if s.boolean {
+			value := &boolValue{}
+			flags.Var(value, s.flag, usage)
+			l.values[s.flag] = &value.value
+			continue
+		}
+
+		l.values[s.flag] = flags.String(s.flag, "", usage)
+	}
+
+	return l
+}
+
+// File returns the configuration file path, empty if there is none.
+func (l *Loader) File() string {
+	if *l.configFile != "" {
+		return *l.configFile
+	}
+
+	return os.Getenv("CONFIG_FILE")
+}
+
+func (l *Loader) Load() (Config, error) {
+	cfg := Default()
+
+	if configFile := l.File(); configFile != "" {
+		if err := loadFile(&cfg, configFile); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if value := os.Getenv(s.env); value != "" {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s environment variable: %w", s.env, err)
+			}
+		}
+	}
+
+	setFlags := make(map[string]bool)
+	l.flags.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	for _, s := range settings {
+		if setFlags[s.flag] {
+			if err := s.set(&cfg, *l.values[s.flag]); err != nil {
+				return Config{}, fmt.Errorf("invalid -%s flag: %w", s.flag, err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	// An empty file is a valid configuration.
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error parsing configuration file '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+// boolValue is the flag value of the boolean settings. It keeps the value as given, like the other settings, and
+// is set to true when the flag has no value.
+type boolValue struct {
+	value string
+}
+
+func (v *boolValue) String() string { return v.value }
+
+func (v *boolValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *boolValue) IsBoolFlag() bool { return true }
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(cfg *Config, value string) error {
+		*field(cfg) = value
+		return nil
+	}
+}
+
+func setDuration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(cfg *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		*field(cfg) = d
+		return nil
+	}
+}
+
+func setBool(field func(*Config) *bool) func(*Config, string) error {
+	return func(cfg *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		*field(cfg) = b
+		return nil
+	}
+}
+
+func setOwners(cfg *Config, value string) error {
+	var owners []int64
+	for _, id := range strings.Split(value, ",") {
+		owner, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid '%s' owner Telegram ID: %w", id, err)
+		}
+
+		owners = append(owners, owner)
+	}
+
+	cfg.Telegram.Owners = owners
+	return nil
+}
+
+func setMessagesPerSecond(cfg *Config, value string) (err error) {
+	cfg.RateLimits.MessagesPerSecond, err = strconv.ParseFloat(value, 64)
+	return err
+}
+
+func setBurst(cfg *Config, value string) (err error) {
+	cfg.RateLimits.Burst, err = strconv.Atoi(value)
+	return err
+}
+
+func setScreenshotMaxSize(cfg *Config, value string) (err error) {
+	cfg.Screenshots.MaxSize, err = strconv.ParseInt(value, 10, 64)
+	return err
+}
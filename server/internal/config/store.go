@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Store holds the current configuration and reloads it at runtime. Only the owners, targets, rate limits, results,
+// audit and screenshots retention, subscriptions and templates are reloaded: the rest of the settings require a
+// restart.
+type Store struct {
+	loader  *Loader
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(Config)
+}
+
+func NewStore(loader *Loader, cfg Config) *Store {
+	s := &Store{loader: loader}
+	s.current.Store(&cfg)
+
+	return s
+}
+
+// Current returns the configuration in use, it must not be modified.
+func (s *Store) Current() Config {
+	return *s.current.Load()
+}
+
+// OnChange registers a function called with the new configuration every time it's reloaded.
+func (s *Store) OnChange(listener func(Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.listeners = append(s.listeners, listener)
+}
+
+// Reload loads the configuration again and applies its safe changes, an invalid configuration is rejected.
+func (s *Store) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded, err := s.loader.Load()
+	if err != nil {
+		return fmt.Errorf("configuration rejected: %w", err)
+	}
+
+	old := s.Current()
+
+	cfg := old
+	cfg.Telegram.Owners = loaded.Telegram.Owners
+	cfg.Targets = loaded.Targets
+	cfg.RateLimits = loaded.RateLimits
+	cfg.Results = loaded.Results
+	cfg.Audit = loaded.Audit
+	cfg.Screenshots = loaded.Screenshots
+	cfg.Subscriptions = loaded.Subscriptions
+	cfg.Templates = loaded.Templates
+
+	if restart := Diff(cfg, loaded); len(restart) > 0 {
+		slog.Warn("configuration changes that require a restart ignored", slog.Any("changes", restart))
+	}
+
+	changes := Diff(old, cfg)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	s.current.Store(&cfg)
+	slog.Info("configuration reloaded", slog.Any("changes", changes))
+
+	for _, listener := range s.listeners {
+		listener(cfg)
+	}
+
+	return nil
+}
+
+// NotifyHangup relays the SIGHUP signals to the returned channel, to be passed to Watch. It must be called before
+// loading the configuration: the signals received until then would terminate the process, and the ones received
+// before Watch starts are kept until then.
+func NotifyHangup() <-chan os.Signal {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+
+	return hangup
+}
+
+// Watch reloads the configuration on the hangup signals and when the configuration file modification time
+// changes, which is checked every interval, until the context is done.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, hangup <-chan os.Signal) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastModTime := s.fileModTime()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-hangup:
+			slog.Info("SIGHUP received, reloading configuration")
+
+		case <-ticker.C:
+			modTime := s.fileModTime()
+			if modTime.Equal(lastModTime) {
+				continue
+			}
+
+			lastModTime = modTime
+			slog.Info("configuration file changed, reloading configuration")
+		}
+
+		if err := s.Reload(); err != nil {
+			slog.Error("error reloading configuration", slog.Any("error", err))
+		}
+	}
+}
+
+func (s *Store) fileModTime() time.Time {
+	file := s.loader.File()
+	if file == "" {
+		return time.Time{}
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+// Diff returns the settings that differ between both configurations. The secrets are compared as they are but
+// shown masked.
+func Diff(old, new Config) []string {
+	oldValues, newValues := flatten(old), flatten(new)
+	oldMasked, newMasked := flatten(old.Masked()), flatten(new.Masked())
+
+	var changes []string
+	for key, newValue := range newValues {
+		if oldValue := oldValues[key]; !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, fmt.Sprintf("%s: %v -> %v", key, oldMasked[key], newMasked[key]))
+		}
+	}
+
+	slices.Sort(changes)
+	return changes
+}
+
+// flatten returns the configuration settings indexed by their YAML path (e.g. "rate_limits.burst").
+func flatten(cfg Config) map[string]any {
+	values := make(map[string]any)
+
+	var tree map[string]any
+	if out, err := yaml.Marshal(cfg); err == nil {
+		_ = yaml.Unmarshal(out, &tree)
+	}
+
+	var walk func(prefix string, node map[string]any)
+	walk = func(prefix string, node map[string]any) {
+		for key, value := range node {
+			if child, ok := value.(map[string]any); ok {
+				walk(prefix+key+".", child)
+				continue
+			}
+
+			values[prefix+key] = value
+		}
+	}
+	walk("", tree)
+
+	return values
+}
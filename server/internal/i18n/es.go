@@ -0,0 +1,187 @@
+package i18n
+
+// Spanish is the language of most of the bot users.
+const Spanish = "es"
+
+func init() {
+	Register(Spanish, Catalog{
+		// Bot description and command menu.
+		`This bot will try to help you getting a Montevideo's Spain Consulate booking hour by notifying you when the booking system shows hour availability.
+
+Privacy: I save your Telegram user ID, language, /settings, /subscribe target, answers to the notifications and when you were notified, and I log the commands you send.
+
+Disclaimer: this bot was not created by the Spain Consulate and is not an official channel; it just sends you a message when it detects hour availability.`: `Este bot te ayuda a conseguir hora en el Consulado de España en Montevideo avisándote cuando el sistema de reservas muestra horas disponibles.
+
+Privacidad: guardo tu ID de usuario de Telegram, tu idioma, tus /settings, el destino de /subscribe, tus respuestas a los avisos y cuándo te avisé, y registro los comandos que enviás.
+
+Aviso: este bot no fue creado por el Consulado de España ni es un canal oficial de comunicación; solo te envía un mensaje cuando detecta horas disponibles.`,
+		"Returns your user's Telegram ID":                                  "Muestra tu ID de usuario de Telegram",
+		"Lists the commands you can use":                                   "Lista los comandos que podés usar",
+		"Subscribe to Spain Consulate Hour check":                          "Suscribirse al aviso de horas del Consulado de España",
+		"Unsubscribe from Spain Consulate Hour check":                      "Desuscribirse del aviso de horas del Consulado de España",
+		"Change the bot language":                                          "Cambiar el idioma del bot",
+		"Send the debug results to the owners":                             "Enviar los resultados de depuración a los dueños",
+		"Stop sending the debug results to the owners":                     "Dejar de enviar los resultados de depuración a los dueños",
+		"Show the subscribers and debug status":                            "Mostrar los suscriptores y el estado de depuración",
+		"Grant a role to a user":                                           "Asignar un rol a un usuario",
+		"Revoke the role of a user":                                        "Quitar el rol de un usuario",
+		"List the users with a role":                                       "Listar los usuarios con rol",
+		"Tell us you got your appointment":                                 "Avisanos que conseguiste tu hora",
+		"Change your notification settings":                                "Cambiar la configuración de los avisos",
+		"Send a message, and optionally a photo, to every subscriber":      "Enviar un mensaje, y opcionalmente una foto, a todos los suscriptores",
+		"Show when the hours are usually available":                        "Mostrar cuándo suele haber horas disponibles",
+		"Show the latest check of each page":                               "Mostrar el último chequeo de cada página",
+		"Show the availability outcomes statistics":                        "Mostrar las estadísticas de los resultados de los avisos",
+		"Show the latest audit log entries":                                "Mostrar las últimas entradas del registro de auditoría",
+		"Available commands:":                                              "Comandos disponibles:",
+		"Sorry, the commands could not be listed, please try again later.": "Perdón, no se pudieron listar los comandos, intentá de nuevo más tarde.",
+
+		// Command replies.
+		"Your Telegram user ID is: `%d`": "Tu ID de usuario de Telegram es: `%d`",
+		"Welcome to this friendly bot!":  "¡Bienvenido a este bot amigable!",
+		`Welcome!
+
+Use /subscribe command to subscribe to the hour availability notification.
+Use /unsubscribe command to stop receiving notifications.
+Use /language command to change the language.
+`: `¡Bienvenido!
+
+Usá el comando /subscribe para suscribirte al aviso de horas disponibles.
+Usá el comando /unsubscribe para dejar de recibir avisos.
+Usá el comando /language para cambiar el idioma.
+`,
+		"User subscribed":                                          "Usuario suscrito",
+		"User subscribed to the '%s' target":                       "Usuario suscrito al objetivo '%s'",
+		"unknown target '%s', it must be one of: %s":               "objetivo desconocido '%s', debe ser uno de: %s",
+		"Error subscribing to the notifications":                   "Error al suscribirse a los avisos",
+		"User unsubscribed":                                        "Usuario desuscrito",
+		"Error unsubscribing to the notifications":                 "Error al desuscribirse de los avisos",
+		"Debug enabled":                                            "Depuración habilitada",
+		"Error enabling debug":                                     "Error al habilitar la depuración",
+		"Debug disabled":                                           "Depuración deshabilitada",
+		"Error disabling debug":                                    "Error al deshabilitar la depuración",
+		"System status:\n\nDebug status: %t\nSubscriptions: %+v\n": "Estado del sistema:\n\nDepuración: %t\nSuscripciones: %+v\n",
+		"Error getting debug status":                               "Error al obtener el estado de depuración",
+		"Error getting subscribers":                                "Error al obtener los suscriptores",
+		"Role '%s' granted to user %d":                             "Rol '%s' asignado al usuario %d",
+		"Error granting role":                                      "Error al asignar el rol",
+		"Owners set in the configuration can't be revoked":         "Los dueños definidos en la configuración no se pueden quitar",
+		"Role revoked from user %d":                                "Rol quitado al usuario %d",
+		"Error revoking role":                                      "Error al quitar el rol",
+		"Error getting roles":                                      "Error al obtener los roles",
+		"Roles:":                                                   "Roles:",
+		"Your language is '%s', use /language <%s> to change it.":  "Tu idioma es '%s', usá /language <%s> para cambiarlo.",
+		"Error setting the language":                               "Error al cambiar el idioma",
+		"Language set to '%s'":                                     "Idioma cambiado a '%s'",
+		"This is a test message, the notifications are working.":   "Este es un mensaje de prueba, los avisos funcionan.",
+
+		// Broadcasts.
+		"Send this message to %d subscribers?":  "¿Enviar este mensaje a %d suscriptores?",
+		"Send":                                  "Enviar",
+		"Cancel":                                "Cancelar",
+		"This broadcast is no longer available": "Este envío ya no está disponible",
+		"Broadcast cancelled":                   "Envío cancelado",
+		"Sending the broadcast to %d subscribers...":   "Enviando el mensaje a %d suscriptores...",
+		"Broadcast finished: %d delivered, %d failed.": "Envío terminado: %d entregados, %d fallidos.",
+		"Error getting the broadcast photo":            "Error al obtener la foto del envío",
+
+		// Notification settings.
+		"Notification settings, press a button to change it.\n\n" +
+			"Use /settings quiet <HH:MM-HH:MM|off> to set other quiet hours, " +
+			"and /settings timezone <zone> (e.g. America/Montevideo) to set their time zone.": "Configuración de los avisos, tocá un botón para cambiarla.\n\n" +
+			"Usá /settings quiet <HH:MM-HH:MM|off> para elegir otro horario de silencio, " +
+			"y /settings timezone <zona> (por ejemplo America/Montevideo) para elegir su zona horaria.",
+		"Error getting your settings":      "Error al obtener tu configuración",
+		"Error saving your settings":       "Error al guardar tu configuración",
+		"This menu is no longer available": "Este menú ya no está disponible",
+		"Sorry, %s.":                       "Perdón, %s.",
+		"on":                               "sí",
+		"off":                              "no",
+		"every check":                      "en cada chequeo",
+		"every %s":                         "cada %s",
+		"Sound: %s":                        "Sonido: %s",
+		"Screenshots: %s":                  "Capturas: %s",
+		"Quiet hours: %s":                  "Horario de silencio: %s",
+		"Reminders: %s":                    "Recordatorios: %s",
+		"invalid quiet hours, use HH:MM-HH:MM or off":           "horario de silencio inválido, usá HH:MM-HH:MM u off",
+		"unknown time zone, use a name like America/Montevideo": "zona horaria desconocida, usá un nombre como America/Montevideo",
+
+		// Subscription expiration.
+		"Are you still looking for an appointment? If you don't answer within %s you will be unsubscribed.": "¿Seguís buscando hora? Si no respondés en %s se cancelará tu suscripción.",
+		"Yes, keep notifying me": "Sí, seguí avisándome",
+		"No, unsubscribe me":     "No, desuscribime",
+		"You were unsubscribed because you didn't confirm your subscription. Use /subscribe to subscribe again.": "Se canceló tu suscripción porque no la confirmaste. Usá /subscribe para suscribirte de nuevo.",
+		"You are not subscribed, use /subscribe to subscribe again.":                                             "No estás suscrito, usá /subscribe para suscribirte de nuevo.",
+		"Great, you will keep getting the notifications. Good luck!":                                             "Perfecto, vas a seguir recibiendo los avisos. ¡Suerte!",
+		"Error confirming your subscription":                                                                     "Error al confirmar tu suscripción",
+
+		// Latest checks.
+		"Latest checks (%s time):":     "Últimos chequeos (hora de %s):",
+		"Not checked yet.":             "Todavía no se chequeó.",
+		"Error getting the last check": "Error al obtener el último chequeo",
+		"Last check: %s (%s ago)":      "Último chequeo: %s (hace %s)",
+		"Result: %s":                   "Resultado: %s",
+		"Status: the checks are delayed, the scrapper may not be working.":  "Estado: los chequeos están atrasados, puede que el scrapper no esté funcionando.",
+		"Status: the page layout changed, the results may be wrong.":        "Estado: cambió el diseño de la página, puede que los resultados sean incorrectos.",
+		"Status: the latest check failed, the scrapper may not be working.": "Estado: el último chequeo falló, puede que el scrapper no esté funcionando.",
+		"Status: working.":      "Estado: funcionando.",
+		"Next check: around %s": "Próximo chequeo: alrededor de las %s",
+
+		// Availability statistics.
+		"Error getting the statistics":                 "Error al obtener las estadísticas",
+		"There are no checks in the last %d days yet.": "Todavía no hay chequeos en los últimos %d días.",
+		"Availability in the last %d days (%s time):":  "Disponibilidad en los últimos %d días (hora de %s):",
+		"Availability windows: %d":                     "Períodos con horas disponibles: %d",
+		"Failed checks: %d":                            "Chequeos fallidos: %d",
+		"Average window duration: %s":                  "Duración promedio: %s",
+		"Hours are available right now!":               "¡Hay horas disponibles ahora mismo!",
+		"Last availability: %s ago":                    "Última disponibilidad: hace %s",
+		"Most windows started on: %s":                  "Días en que más aparecieron: %s",
+		"Most windows started at: %s":                  "Horas en que más aparecieron: %s",
+		"Sunday":                                       "domingo",
+		"Monday":                                       "lunes",
+		"Tuesday":                                      "martes",
+		"Wednesday":                                    "miércoles",
+		"Thursday":                                     "jueves",
+		"Friday":                                       "viernes",
+		"Saturday":                                     "sábado",
+
+		// Appointment outcomes.
+		"I booked":                 "Reservé",
+		"I missed it":              "No llegué",
+		"Not interested":           "No me interesa",
+		"Error saving your answer": "Error al guardar tu respuesta",
+		"There was no recent availability to record your appointment for.":                    "No hubo horas disponibles recientemente para registrar tu reserva.",
+		"Thanks for letting us know!":                                                         "¡Gracias por avisarnos!",
+		"Congratulations on your appointment! Do you want to stop getting the notifications?": "¡Felicitaciones por tu hora! ¿Querés dejar de recibir los avisos?",
+		"Yes, unsubscribe me":        "Sí, desuscribime",
+		"No, keep notifying me":      "No, seguí avisándome",
+		"Error getting the outcomes": "Error al obtener los resultados",
+		"Outcomes:":                  "Resultados:",
+		"Subscribers that booked: %d\nAvailability events reported: %d\nSuccess rate: %.1f%%": "Suscriptores que reservaron: %d\nAvisos con respuesta: %d\nTasa de éxito: %.1f%%",
+
+		// Audit log.
+		"Error getting the audit log": "Error al obtener el registro de auditoría",
+		"The audit log is empty":      "El registro de auditoría está vacío",
+		"Latest audit log entries:":   "Últimas entradas del registro de auditoría:",
+		"succeeded":                   "correcto",
+		"denied":                      "denegado",
+		"failed":                      "falló",
+
+		// Command errors.
+		"Sorry, %s.\n\nUsage: %s":                                "Perdón, %s.\n\nUso: %s",
+		"missing <%s> argument":                                  "falta el argumento <%s>",
+		"invalid <%s> argument '%s', it must be one of: %s":      "argumento <%s> inválido '%s', debe ser uno de: %s",
+		"invalid <%s> argument '%s', it must be a number":        "argumento <%s> inválido '%s', debe ser un número",
+		"too many arguments":                                     "demasiados argumentos",
+		"Sorry, you are not authorized to use this command.":     "Perdón, no tenés permiso para usar este comando.",
+		"Too many commands, please wait a moment and try again.": "Demasiados comandos, esperá un momento e intentá de nuevo.",
+
+		// Notifications, including the scrapper messages and the default templates.
+		"There are hours available":                             "Hay horas disponibles",
+		"There are no available hours":                          "No hay horas disponibles",
+		"Spain Consulate passport booking":                      "Reserva de pasaportes del Consulado de España",
+		"Page layout changed for target '%s' (distance %d/64).": "Cambió el diseño de la página del objetivo '%s' (distancia %d/64).",
+		"Page layout changed for target '{{.Target}}' (distance {{.Distance}}/64), the scrapper selectors may be broken.": "Cambió el diseño de la página del objetivo '{{.Target}}' (distancia {{.Distance}}/64), puede que los selectores del scrapper estén rotos.",
+	})
+}
@@ -0,0 +1,81 @@
+// Package i18n translates the bot messages. The messages are written in English in the code and used as the keys
+// of the catalogs of the other languages, so a missing translation falls back to the English message.
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// English is the language the messages are written in, it doesn't need a catalog.
+const English = "en"
+
+// Catalog maps the English messages, fmt verbs included, to their translation.
+type Catalog map[string]string
+
+var (
+	mu       sync.RWMutex
+	catalogs = map[string]Catalog{English: {}}
+)
+
+// Register adds the catalog of a language, replacing the previous one if any.
+func Register(language string, catalog Catalog) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	catalogs[language] = catalog
+}
+
+// Languages returns the supported language codes, sorted.
+func Languages() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	slices.Sort(languages)
+
+	return languages
+}
+
+// Supported reports whether the language has a catalog.
+func Supported(language string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := catalogs[language]
+	return ok
+}
+
+// Match returns the supported language of an IETF language tag like the Telegram users language_code (e.g.
+// "es-UY" matches "es"), empty if it's not supported.
+func Match(code string) string {
+	language, _, _ := strings.Cut(strings.ToLower(code), "-")
+	if !Supported(language) {
+		return ""
+	}
+
+	return language
+}
+
+// T translates the message to the language and formats it with the args, if any. Messages without translation
+// are returned in English.
+func T(language, message string, args ...any) string {
+	mu.RLock()
+	translated, ok := catalogs[language][message]
+	mu.RUnlock()
+
+	if ok {
+		message = translated
+	}
+
+	if len(args) == 0 {
+		return message
+	}
+
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,102 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+const (
+	defaultAuditEntries = 10
+	// maxAuditEntries and maxAuditTargetLength keep the reply under the Telegram message length limit.
+	maxAuditEntries      = 20
+	maxAuditTargetLength = 60
+)
+
+// BotAuditHandler shows the owners the latest audit log entries.
+type BotAuditHandler struct {
+	log    *audit.Log
+	config *config.Store
+}
+
+func NewBotAuditHandler(log *audit.Log, config *config.Store) *BotAuditHandler {
+	return &BotAuditHandler{
+		log:    log,
+		config: config,
+	}
+}
+
+// Audit accepts the optional "count" (int) argument, the amount of entries to show.
+func (h *BotAuditHandler) Audit(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+
+	count := defaultAuditEntries
+	if args := telegrambot.Args(ctx); args.Has("count") {
+		count = int(min(max(args.Int("count"), 1), maxAuditEntries))
+	}
+
+	entries, err := h.log.Entries(repository.AuditFilter{Limit: count})
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting audit entries",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error getting the audit log"))
+		return
+	}
+
+	if len(entries) == 0 {
+		sendMessage(ctx, b, chatID, i18n.T(language, "The audit log is empty"))
+		return
+	}
+
+	location, err := time.LoadLocation(h.config.Current().Telegram.DefaultTimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(language, "Latest audit log entries:"))
+	message.WriteString("\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&message, "\n%s %s %s", entry.CreatedAt.In(location).Format("2006-01-02 15:04:05"), entry.Actor, entry.Action)
+		if target := []rune(entry.Target); len(target) > maxAuditTargetLength {
+			fmt.Fprintf(&message, " %s…", string(target[:maxAuditTargetLength]))
+		} else if len(target) > 0 {
+			fmt.Fprintf(&message, " %s", entry.Target)
+		}
+
+		fmt.Fprintf(&message, ": %s", auditOutcomeLabel(language, entry.Outcome))
+		if entry.Error != "" {
+			fmt.Fprintf(&message, " (%s)", entry.Error)
+		}
+	}
+
+	sendMessage(ctx, b, chatID, message.String())
+}
+
+func auditOutcomeLabel(language string, outcome repository.AuditOutcome) string {
+	switch outcome {
+	case repository.AuditSucceeded:
+		return i18n.T(language, "succeeded")
+	case repository.AuditDenied:
+		return i18n.T(language, "denied")
+	case repository.AuditFailed:
+		return i18n.T(language, "failed")
+	}
+
+	return string(outcome)
+}
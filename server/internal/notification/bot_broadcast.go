@@ -0,0 +1,374 @@
+package notification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+const (
+	// BroadcastCallbackPrefix prefixes the callback data of the broadcast preview buttons.
+	BroadcastCallbackPrefix = "broadcast:"
+
+	// broadcastTTL is how long a broadcast preview waits to be confirmed.
+	broadcastTTL = time.Hour
+	// broadcastReportTTL is how long a confirmed broadcast waits for its delivery report, the notifications lost
+	// on the way would keep it waiting forever.
+	broadcastReportTTL = 24 * time.Hour
+	// maxBroadcastPhotoSize is the biggest photo downloaded from Telegram, which limits the bot photos to 10MB.
+	maxBroadcastPhotoSize = 10 << 20
+)
+
+// broadcast is a message sent by an admin to every subscriber.
+type broadcast struct {
+	// adminID is the user that created the broadcast, chatID is where the preview and report are sent.
+	adminID   int64
+	chatID    int64
+	language  string
+	message   string
+	photoID   string
+	createdAt time.Time
+	// confirmedAt is when the admin confirmed the broadcast.
+	confirmedAt time.Time
+
+	// The delivery counters, set once the broadcast is confirmed.
+	total     int
+	delivered int
+	failed    int
+}
+
+// BotBroadcastHandler lets the admins send a message to every subscriber. The broadcasts are previewed to the admin,
+// who has to confirm them, and sent through the notifications topic; the admin gets the delivery report when all
+// the notifications are handled.
+type BotBroadcastHandler struct {
+	ctx context.Context
+
+	bot       *telegrambot.TelegramBot
+	db        repository.Repository
+	publisher Publisher
+	objects   ObjectStore
+
+	mu sync.Mutex
+	// pending are the broadcasts waiting for confirmation and sending the ones waiting for their delivery report,
+	// by broadcast ID.
+	pending map[string]*broadcast
+	sending map[string]*broadcast
+}
+
+func NewBotBroadcastHandler(
+	ctx context.Context,
+	bot *telegrambot.TelegramBot,
+	db repository.Repository,
+	publisher Publisher,
+	objects ObjectStore,
+) *BotBroadcastHandler {
+	return &BotBroadcastHandler{
+		ctx:       ctx,
+		bot:       bot,
+		db:        db,
+		publisher: publisher,
+		objects:   objects,
+		pending:   make(map[string]*broadcast),
+		sending:   make(map[string]*broadcast),
+	}
+}
+
+// Broadcast expects the "message" argument, the command may be the caption of the photo to broadcast.
+func (h *BotBroadcastHandler) Broadcast(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+
+	adminID := chatID
+	if update.Message.From != nil {
+		adminID = update.Message.From.ID
+	}
+
+	pending := &broadcast{
+		adminID:   adminID,
+		chatID:    chatID,
+		language:  language,
+		message:   telegrambot.Args(ctx).String("message"),
+		createdAt: time.Now(),
+	}
+
+	if photos := update.Message.Photo; len(photos) > 0 {
+		// The photo sizes are sorted from the smallest to the biggest one.
+		pending.photoID = photos[len(photos)-1].FileID
+	}
+
+	subs, err := h.db.Subscribers()
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting subscribers",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error getting subscribers"))
+		return
+	}
+
+	id := newBroadcastID()
+	h.mu.Lock()
+	h.removeExpired()
+	h.pending[id] = pending
+	h.mu.Unlock()
+
+	// Preview the broadcast as the subscribers will get it: the message followed by the photo.
+	sendMessage(ctx, b, chatID, pending.message)
+	if pending.photoID != "" {
+		_, err := b.SendPhoto(ctx, &bot.SendPhotoParams{
+			ChatID: chatID,
+			Photo:  &models.InputFileString{Data: pending.photoID},
+		})
+		if err != nil {
+			telegrambot.Logger(ctx).Error("error sending photo",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+			telegrambot.CommandFailed(ctx, err)
+		}
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   i18n.T(language, "Send this message to %d subscribers?", len(subs)),
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: i18n.T(language, "Send"), CallbackData: BroadcastCallbackPrefix + "confirm:" + id},
+				{Text: i18n.T(language, "Cancel"), CallbackData: BroadcastCallbackPrefix + "cancel:" + id},
+			}},
+		},
+	})
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error sending broadcast confirmation",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+	}
+}
+
+// Callback handles the preview buttons, only the admin that created the broadcast can press them, as long as they
+// keep the role required by the BroadcastCallbackPrefix.
+func (h *BotBroadcastHandler) Callback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	language := telegrambot.Language(ctx)
+
+	action, id, _ := strings.Cut(strings.TrimPrefix(query.Data, BroadcastCallbackPrefix), ":")
+
+	h.mu.Lock()
+	h.removeExpired()
+	pending, ok := h.pending[id]
+	if ok && pending.adminID == query.From.ID {
+		delete(h.pending, id)
+	}
+	h.mu.Unlock()
+
+	if !ok || pending.adminID != query.From.ID {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "This broadcast is no longer available"))
+		return
+	}
+
+	answerCallback(ctx, b, query.ID, "")
+	if message := query.Message.Message; message != nil {
+		removeKeyboard(ctx, b, message)
+	}
+
+	if action != "confirm" {
+		sendMessage(ctx, b, pending.chatID, i18n.T(language, "Broadcast cancelled"))
+		return
+	}
+
+	if err := h.send(ctx, b, id, pending); err != nil {
+		telegrambot.CommandFailed(ctx, err)
+	}
+}
+
+// send publishes the broadcast notification of each subscriber.
+func (h *BotBroadcastHandler) send(ctx context.Context, b *bot.Bot, id string, pending *broadcast) error {
+	var imageRef string
+	if pending.photoID != "" {
+		var err error
+		imageRef, err = h.storePhoto(ctx, b, pending.photoID)
+		if err != nil {
+			slog.Error("error storing broadcast photo",
+				slog.Int64("chat_id", pending.chatID),
+				slog.Any("error", err),
+			)
+			sendMessage(ctx, b, pending.chatID, i18n.T(pending.language, "Error getting the broadcast photo"))
+			return err
+		}
+	}
+
+	subs, err := h.db.Subscribers()
+	if err != nil {
+		slog.Error("error getting subscribers", slog.Any("error", err))
+		sendMessage(ctx, b, pending.chatID, i18n.T(pending.language, "Error getting subscribers"))
+		return err
+	}
+
+	h.mu.Lock()
+	pending.total = len(subs)
+	pending.confirmedAt = time.Now()
+	h.sending[id] = pending
+	h.mu.Unlock()
+
+	slog.Info("broadcast confirmed",
+		slog.String("broadcast_id", id),
+		slog.Int64("admin_id", pending.adminID),
+		slog.Int("subscribers", len(subs)),
+	)
+
+	sendMessage(ctx, b, pending.chatID, i18n.T(pending.language, "Sending the broadcast to %d subscribers...", len(subs)))
+	for _, subscriber := range subs {
+		err := publishNotification(h.publisher, notifyPayload{
+			Recipient:   subscriber,
+			Message:     pending.message,
+			notifyImage: notifyImage{ImageRef: imageRef},
+			BroadcastID: id,
+		})
+		if err != nil {
+			slog.Error("error publishing message",
+				slog.String("destiny_topic", NotifierTopicName),
+				slog.Int64("recipient", subscriber),
+				slog.String("broadcast_id", id),
+				slog.Any("error", err),
+			)
+			h.Delivered(id, err)
+		}
+	}
+
+	// There is nothing to wait for without subscribers.
+	h.finish(id)
+	return nil
+}
+
+// Delivered records the delivery of a broadcast notification.
+func (h *BotBroadcastHandler) Delivered(id string, err error) {
+	h.mu.Lock()
+	h.removeExpired()
+	sending, ok := h.sending[id]
+	if ok && err != nil {
+		sending.failed++
+	} else if ok {
+		sending.delivered++
+	}
+	h.mu.Unlock()
+
+	h.finish(id)
+}
+
+// finish reports the delivery counters to the admin once all the broadcast notifications are handled.
+func (h *BotBroadcastHandler) finish(id string) {
+	h.mu.Lock()
+	sending, ok := h.sending[id]
+	if !ok || sending.delivered+sending.failed < sending.total {
+		h.mu.Unlock()
+		return
+	}
+
+	delete(h.sending, id)
+	h.mu.Unlock()
+
+	slog.Info("broadcast finished",
+		slog.String("broadcast_id", id),
+		slog.Int("delivered", sending.delivered),
+		slog.Int("failed", sending.failed),
+	)
+
+	message := i18n.T(sending.language, "Broadcast finished: %d delivered, %d failed.", sending.delivered, sending.failed)
+	if err := h.bot.SendMessage(h.ctx, sending.chatID, message); err != nil {
+		slog.Error("error sending broadcast report",
+			slog.Int64("chat_id", sending.chatID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// storePhoto downloads the photo from Telegram and puts it in the object store, returning its reference.
+func (h *BotBroadcastHandler) storePhoto(ctx context.Context, b *bot.Bot, fileID string) (string, error) {
+	file, err := b.GetFile(ctx, &bot.GetFileParams{FileID: fileID})
+	if err != nil {
+		return "", fmt.Errorf("error getting file: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.FileDownloadLink(file), nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating download request: %w", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The error includes the download link, which contains the bot token.
+		return "", fmt.Errorf("error downloading file '%s'", file.FilePath)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error downloading file '%s': status %d", file.FilePath, resp.StatusCode)
+	}
+
+	photo, err := io.ReadAll(io.LimitReader(resp.Body, maxBroadcastPhotoSize))
+	if err != nil {
+		return "", fmt.Errorf("error reading file '%s': %w", file.FilePath, err)
+	}
+
+	name := objectName(photo)
+	if err := h.objects.PutObject(ctx, name, photo); err != nil {
+		return "", fmt.Errorf("error putting photo in object store: %w", err)
+	}
+
+	return name, nil
+}
+
+// removeExpired discards the broadcasts not confirmed in time and the ones whose delivery report is overdue, the
+// caller must hold the lock.
+func (h *BotBroadcastHandler) removeExpired() {
+	for id, pending := range h.pending {
+		if time.Since(pending.createdAt) > broadcastTTL {
+			delete(h.pending, id)
+		}
+	}
+
+	for id, sending := range h.sending {
+		if time.Since(sending.confirmedAt) > broadcastReportTTL {
+			slog.Warn("broadcast report expired",
+				slog.String("broadcast_id", id),
+				slog.Int("delivered", sending.delivered),
+				slog.Int("failed", sending.failed),
+				slog.Int("total", sending.total),
+			)
+			delete(h.sending, id)
+		}
+	}
+}
+
+func answerCallback(ctx context.Context, b *bot.Bot, queryID, text string) {
+	_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+		CallbackQueryID: queryID,
+		Text:            text,
+	})
+	if err != nil {
+		slog.Error("error answering callback query", slog.Any("error", err))
+	}
+}
+
+func newBroadcastID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// missedChecksUnhealthy is how many scheduled checks the scrapper can miss before it's reported as not working.
+const missedChecksUnhealthy = 3
+
+// BotLastCheckHandler shows the subscribers that the targets are being checked.
+type BotLastCheckHandler struct {
+	db     repository.Repository
+	config *config.Store
+}
+
+func NewBotLastCheckHandler(db repository.Repository, config *config.Store) *BotLastCheckHandler {
+	return &BotLastCheckHandler{
+		db:     db,
+		config: config,
+	}
+}
+
+// LastCheck replies the latest result of each target the subscriber is notified about, when the next check is
+// expected and the scrapper health.
+func (h *BotLastCheckHandler) LastCheck(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+	cfg := h.config.Current()
+
+	// The times are shown in the subscriber time zone and the targets are the subscribed ones, the profile is
+	// optional.
+	profile, err := h.db.Profile(chatID)
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting profile",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+	}
+
+	timeZone := profile.Preferences.TimeZone
+	if timeZone == "" {
+		timeZone = cfg.Telegram.DefaultTimeZone
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	now := time.Now()
+
+	var message strings.Builder
+	message.WriteString(i18n.T(language, "Latest checks (%s time):", location))
+	for _, target := range subscribedTargets(cfg, profile.Target) {
+		name := target.Name
+		if target.Description != "" {
+			name = i18n.T(language, target.Description)
+		}
+
+		message.WriteString("\n\n")
+		message.WriteString(name)
+		message.WriteString("\n")
+
+		result, err := h.db.LastResult(target.Name)
+		if errors.Is(err, repository.ErrNotFound) {
+			message.WriteString(i18n.T(language, "Not checked yet."))
+			continue
+		}
+
+		if err != nil {
+			telegrambot.Logger(ctx).Error("error getting last result",
+				slog.String("target", target.Name),
+				slog.Any("error", err),
+			)
+			telegrambot.CommandFailed(ctx, err)
+			message.WriteString(i18n.T(language, "Error getting the last check"))
+			continue
+		}
+
+		elapsed := now.Sub(result.CreatedAt)
+		nextCheck := result.CreatedAt.Add(target.Schedule)
+
+		message.WriteString(i18n.T(language, "Last check: %s (%s ago)",
+			result.CreatedAt.In(location).Format(time.DateTime), roundedDuration(elapsed),
+		))
+		message.WriteString("\n")
+		message.WriteString(i18n.T(language, "Result: %s", i18n.T(language, result.Message)))
+		message.WriteString("\n")
+		message.WriteString(i18n.T(language, checkHealth(result, target.Schedule, now)))
+
+		if nextCheck.After(now) {
+			message.WriteString("\n")
+			message.WriteString(i18n.T(language, "Next check: around %s", nextCheck.In(location).Format(time.TimeOnly)))
+		}
+	}
+
+	sendMessage(ctx, b, chatID, message.String())
+}
+
+// subscribedTargets returns the configured targets the subscriber of the given target, empty for all of them, is
+// notified about. The subscribers of a target no longer configured get all of them, its schedule is unknown.
+func subscribedTargets(cfg config.Config, subscribed string) []config.Target {
+	if target, ok := cfg.Target(subscribed); ok && subscribed != "" {
+		return []config.Target{target}
+	}
+
+	return cfg.Targets
+}
+
+// checkHealth returns the scrapper status message of a target given its latest result.
+func checkHealth(result repository.Result, schedule time.Duration, now time.Time) string {
+	switch {
+	case now.Sub(result.CreatedAt) > missedChecksUnhealthy*schedule:
+		return "Status: the checks are delayed, the scrapper may not be working."
+	case result.CheckStatus() == repository.ResultFailed:
+		return "Status: the latest check failed, the scrapper may not be working."
+	case result.LayoutChanged:
+		return "Status: the page layout changed, the results may be wrong."
+	}
+
+	return "Status: working."
+}
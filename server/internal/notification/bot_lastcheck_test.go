@@ -0,0 +1,67 @@
+package notification
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestCheckHealth(t *testing.T) {
+	now := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		result repository.Result
+		want   string
+	}{
+		{"available", repository.Result{CreatedAt: recent, Status: repository.ResultAvailable}, "Status: working."},
+		{"unavailable", repository.Result{CreatedAt: recent, Status: repository.ResultUnavailable}, "Status: working."},
+		{"without status", repository.Result{CreatedAt: recent, Debug: true}, "Status: working."},
+		{"failed", repository.Result{CreatedAt: recent, Debug: true, Status: repository.ResultFailed},
+			"Status: the latest check failed, the scrapper may not be working."},
+		{"layout changed", repository.Result{CreatedAt: recent, LayoutChanged: true},
+			"Status: the page layout changed, the results may be wrong."},
+		{"delayed", repository.Result{CreatedAt: now.Add(-time.Hour), Status: repository.ResultFailed},
+			"Status: the checks are delayed, the scrapper may not be working."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkHealth(tt.result, 5*time.Minute, now); got != tt.want {
+				t.Errorf("checkHealth() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribedTargets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Targets = []config.Target{{Name: "madrid"}, {Name: "barcelona"}}
+
+	tests := []struct {
+		name       string
+		subscribed string
+		want       []string
+	}{
+		{"all targets", "", []string{"madrid", "barcelona"}},
+		{"subscribed target", "barcelona", []string{"barcelona"}},
+		{"unknown target", "sevilla", []string{"madrid", "barcelona"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, target := range subscribedTargets(cfg, tt.subscribed) {
+				got = append(got, target.Name)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("subscribedTargets() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/go-telegram/bot"
+
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+)
+
+func sendMessage(ctx context.Context, b *bot.Bot, chatID int64, text string) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   text,
+	})
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error sending message",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,258 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// OutcomeCallbackPrefix prefixes the callback data of the outcome buttons.
+const OutcomeCallbackPrefix = "outcome:"
+
+// BotOutcomeHandler records what happened to the subscribers after the availability notifications, to know if the
+// bot actually helps.
+type BotOutcomeHandler struct {
+	db repository.Repository
+}
+
+func NewBotOutcomeHandler(db repository.Repository) *BotOutcomeHandler {
+	return &BotOutcomeHandler{
+		db: db,
+	}
+}
+
+// GotIt records a booked outcome for the latest availability event of the subscriber target.
+func (h *BotOutcomeHandler) GotIt(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+
+	profile, err := h.db.Profile(chatID)
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting profile",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error saving your answer"))
+		return
+	}
+
+	result, err := h.db.LastAvailableResult(profile.Target)
+	if errors.Is(err, repository.ErrNotFound) {
+		sendMessage(ctx, b, chatID, i18n.T(language, "There was no recent availability to record your appointment for."))
+		return
+	}
+
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting last available result",
+			slog.Int64("chat_id", chatID),
+			slog.String("target", profile.Target),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error saving your answer"))
+		return
+	}
+
+	h.record(ctx, b, chatID, result.ID, repository.OutcomeBooked, language)
+}
+
+// Callback handles the outcome buttons sent along with the availability notifications, and the unsubscription
+// question that follows a booked outcome.
+func (h *BotOutcomeHandler) Callback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	language := telegrambot.Language(ctx)
+
+	message := query.Message.Message
+	if message == nil {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "This menu is no longer available"))
+		return
+	}
+
+	chatID := message.Chat.ID
+	data := strings.TrimPrefix(query.Data, OutcomeCallbackPrefix)
+
+	switch data {
+	case "unsubscribe":
+		answerCallback(ctx, b, query.ID, "")
+		removeKeyboard(ctx, b, message)
+
+		messageText := i18n.T(language, "User unsubscribed")
+		err := h.db.RemoveSubscriber(chatID)
+		if err != nil {
+			slog.Error("error removing subscriber from DB",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+			telegrambot.CommandFailed(ctx, err)
+			messageText = i18n.T(language, "Error unsubscribing to the notifications")
+		}
+
+		sendMessage(ctx, b, chatID, messageText)
+		return
+
+	case "keep":
+		answerCallback(ctx, b, query.ID, "")
+		removeKeyboard(ctx, b, message)
+		return
+	}
+
+	id, status, _ := strings.Cut(data, ":")
+	resultID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || !slices.Contains(repository.Outcomes, repository.OutcomeStatus(status)) {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "This menu is no longer available"))
+		return
+	}
+
+	answerCallback(ctx, b, query.ID, "")
+	removeKeyboard(ctx, b, message)
+	h.record(ctx, b, chatID, resultID, repository.OutcomeStatus(status), language)
+}
+
+// record stores the outcome and, for the booked ones, asks the subscriber whether they want to unsubscribe.
+func (h *BotOutcomeHandler) record(
+	ctx context.Context,
+	b *bot.Bot,
+	chatID int64,
+	resultID uint64,
+	status repository.OutcomeStatus,
+	language string,
+) {
+	err := h.db.SetOutcome(repository.Outcome{
+		ResultID:   resultID,
+		Subscriber: chatID,
+		Status:     status,
+		CreatedAt:  time.Now(),
+	})
+	if err != nil {
+		slog.Error("error setting outcome",
+			slog.Int64("chat_id", chatID),
+			slog.Uint64("result_id", resultID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error saving your answer"))
+		return
+	}
+
+	if status != repository.OutcomeBooked {
+		sendMessage(ctx, b, chatID, i18n.T(language, "Thanks for letting us know!"))
+		return
+	}
+
+	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: chatID,
+		Text:   i18n.T(language, "Congratulations on your appointment! Do you want to stop getting the notifications?"),
+		ReplyMarkup: &models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: i18n.T(language, "Yes, unsubscribe me"), CallbackData: OutcomeCallbackPrefix + "unsubscribe"},
+				{Text: i18n.T(language, "No, keep notifying me"), CallbackData: OutcomeCallbackPrefix + "keep"},
+			}},
+		},
+	})
+	if err != nil {
+		slog.Error("error sending unsubscription question",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// Outcomes replies the outcome statistics.
+func (h *BotOutcomeHandler) Outcomes(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+
+	outcomes, err := h.db.Outcomes()
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting outcomes",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error getting the outcomes"))
+		return
+	}
+
+	counts := make(map[repository.OutcomeStatus]int)
+	events := make(map[uint64]bool)
+	booked := make(map[int64]bool)
+	for _, outcome := range outcomes {
+		counts[outcome.Status]++
+		events[outcome.ResultID] = true
+		if outcome.Status == repository.OutcomeBooked {
+			booked[outcome.Subscriber] = true
+		}
+	}
+
+	var successRate float64
+	if len(outcomes) > 0 {
+		successRate = 100 * float64(counts[repository.OutcomeBooked]) / float64(len(outcomes))
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(language, "Outcomes:"))
+	message.WriteString("\n")
+	for _, status := range repository.Outcomes {
+		fmt.Fprintf(&message, "\n%s: %d", outcomeLabel(language, status), counts[status])
+	}
+
+	message.WriteString("\n\n")
+	message.WriteString(i18n.T(language, "Subscribers that booked: %d\nAvailability events reported: %d\nSuccess rate: %.1f%%",
+		len(booked), len(events), successRate,
+	))
+
+	sendMessage(ctx, b, chatID, message.String())
+}
+
+// outcomeKeyboard returns the outcome buttons of an availability notification.
+func outcomeKeyboard(language string, resultID uint64) *models.InlineKeyboardMarkup {
+	var buttons []models.InlineKeyboardButton
+	for _, status := range repository.Outcomes {
+		buttons = append(buttons, models.InlineKeyboardButton{
+			Text:         outcomeLabel(language, status),
+			CallbackData: fmt.Sprintf("%s%d:%s", OutcomeCallbackPrefix, resultID, status),
+		})
+	}
+
+	return &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{buttons}}
+}
+
+func outcomeLabel(language string, status repository.OutcomeStatus) string {
+	switch status {
+	case repository.OutcomeBooked:
+		return i18n.T(language, "I booked")
+	case repository.OutcomeMissed:
+		return i18n.T(language, "I missed it")
+	case repository.OutcomeNotInterested:
+		return i18n.T(language, "Not interested")
+	}
+
+	return string(status)
+}
+
+func removeKeyboard(ctx context.Context, b *bot.Bot, message *models.Message) {
+	_, err := b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      message.Chat.ID,
+		MessageID:   message.ID,
+		ReplyMarkup: &models.InlineKeyboardMarkup{InlineKeyboard: [][]models.InlineKeyboardButton{}},
+	})
+	if err != nil {
+		slog.Error("error removing buttons",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
@@ -0,0 +1,114 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/access"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+type BotRolesHandler struct {
+	access *access.Policy
+}
+
+func NewBotRolesHandler(access *access.Policy) *BotRolesHandler {
+	return &BotRolesHandler{
+		access: access,
+	}
+}
+
+// Grant expects the "user_id" (int) and "role" arguments.
+func (r *BotRolesHandler) Grant(ctx context.Context, b *bot.Bot, update *models.Update) {
+	args := telegrambot.Args(ctx)
+	userID := args.Int("user_id")
+	role := repository.Role(args.String("role"))
+
+	language := telegrambot.Language(ctx)
+	messageText := i18n.T(language, "Role '%s' granted to user %d", role, userID)
+
+	err := r.access.Grant(userID, role)
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error setting role",
+			slog.Int64("chat_id", update.Message.Chat.ID),
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		messageText = i18n.T(language, "Error granting role")
+	}
+
+	sendMessage(ctx, b, update.Message.Chat.ID, messageText)
+}
+
+// Revoke expects the "user_id" (int) argument.
+func (r *BotRolesHandler) Revoke(ctx context.Context, b *bot.Bot, update *models.Update) {
+	userID := telegrambot.Args(ctx).Int("user_id")
+	language := telegrambot.Language(ctx)
+
+	if slices.Contains(r.access.ConfiguredOwners(), userID) {
+		sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(language, "Owners set in the configuration can't be revoked"))
+		return
+	}
+
+	messageText := i18n.T(language, "Role revoked from user %d", userID)
+
+	err := r.access.Revoke(userID)
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error removing role",
+			slog.Int64("chat_id", update.Message.Chat.ID),
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		messageText = i18n.T(language, "Error revoking role")
+	}
+
+	sendMessage(ctx, b, update.Message.Chat.ID, messageText)
+}
+
+func (r *BotRolesHandler) Roles(ctx context.Context, b *bot.Bot, update *models.Update) {
+	roles, err := r.access.UserRoles()
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting roles",
+			slog.Int64("chat_id", update.Message.Chat.ID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), "Error getting roles"))
+		return
+	}
+
+	userIDs := make([]int64, 0, len(roles))
+	for userID := range roles {
+		userIDs = append(userIDs, userID)
+	}
+	slices.Sort(userIDs)
+
+	var message strings.Builder
+	message.WriteString(i18n.T(telegrambot.Language(ctx), "Roles:"))
+	message.WriteString("\n")
+	for _, userID := range userIDs {
+		fmt.Fprintf(&message, "\n%d: %s", userID, roles[userID])
+	}
+
+	sendMessage(ctx, b, update.Message.Chat.ID, message.String())
+}
+
+// RoleNames lists the valid roles names, from the lowest to the highest.
+func RoleNames() []string {
+	names := make([]string, 0, len(repository.Roles))
+	for _, role := range repository.Roles {
+		names = append(names, string(role))
+	}
+
+	return names
+}
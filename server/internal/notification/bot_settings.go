@@ -0,0 +1,298 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// SettingsCallbackPrefix prefixes the callback data of the /settings menu buttons.
+const SettingsCallbackPrefix = "settings:"
+
+var (
+	// quietHoursPresets and reminderPresets are the values cycled by the /settings menu buttons.
+	quietHoursPresets = [][2]string{{"", ""}, {"22:00", "08:00"}, {"23:00", "07:00"}, {"00:00", "09:00"}}
+	reminderPresets   = []time.Duration{0, 30 * time.Minute, time.Hour, 3 * time.Hour, 24 * time.Hour}
+)
+
+// BotSettingsHandler lets the subscribers change their notification preferences, which are stored by chat.
+type BotSettingsHandler struct {
+	db     repository.Repository
+	config *config.Store
+}
+
+func NewBotSettingsHandler(db repository.Repository, config *config.Store) *BotSettingsHandler {
+	return &BotSettingsHandler{
+		db:     db,
+		config: config,
+	}
+}
+
+// SettingNames lists the settings that can be set through the /settings arguments.
+func SettingNames() []string {
+	return []string{"quiet", "timezone"}
+}
+
+// Settings expects the optional "setting" and "value" arguments, without them replies the settings menu.
+func (h *BotSettingsHandler) Settings(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+	args := telegrambot.Args(ctx)
+
+	if args.Has("setting") && !args.Has("value") {
+		sendMessage(ctx, b, chatID, i18n.T(language, "Sorry, %s.", i18n.T(language, "missing <%s> argument", "value")))
+		return
+	}
+
+	if !args.Has("setting") {
+		profile, err := h.db.Profile(chatID)
+		if err != nil {
+			telegrambot.Logger(ctx).Error("error getting profile",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+			telegrambot.CommandFailed(ctx, err)
+			sendMessage(ctx, b, chatID, i18n.T(language, "Error getting your settings"))
+			return
+		}
+
+		h.sendMenu(ctx, b, chatID, language, profile.Preferences)
+		return
+	}
+
+	var invalid error
+	profile, err := h.db.UpdateProfile(chatID, func(profile *repository.Profile) error {
+		invalid = setPreference(&profile.Preferences, args.String("setting"), args.String("value"))
+		return invalid
+	})
+
+	switch {
+	case invalid != nil:
+		sendMessage(ctx, b, chatID, i18n.T(language, "Sorry, %s.", i18n.T(language, invalid.Error())))
+
+	case err != nil:
+		telegrambot.Logger(ctx).Error("error updating profile",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error saving your settings"))
+
+	default:
+		h.sendMenu(ctx, b, chatID, language, profile.Preferences)
+	}
+}
+
+// sendMenu sends the settings menu, with the current preferences.
+func (h *BotSettingsHandler) sendMenu(ctx context.Context, b *bot.Bot, chatID int64, language string, prefs repository.Preferences) {
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID:      chatID,
+		Text:        settingsText(language),
+		ReplyMarkup: h.settingsKeyboard(language, prefs),
+	})
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error sending settings menu",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+	}
+}
+
+// Callback handles the settings menu buttons, each one cycles the values of a setting.
+func (h *BotSettingsHandler) Callback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	language := telegrambot.Language(ctx)
+
+	message := query.Message.Message
+	if message == nil {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "This menu is no longer available"))
+		return
+	}
+
+	profile, err := h.db.UpdateProfile(message.Chat.ID, func(profile *repository.Profile) error {
+		prefs := &profile.Preferences
+		switch strings.TrimPrefix(query.Data, SettingsCallbackPrefix) {
+		case "sound":
+			prefs.Silent = !prefs.Silent
+		case "screenshots":
+			prefs.HideScreenshots = !prefs.HideScreenshots
+		case "quiet":
+			i := slices.Index(quietHoursPresets, [2]string{prefs.QuietStart, prefs.QuietEnd})
+			next := quietHoursPresets[(i+1)%len(quietHoursPresets)]
+			prefs.QuietStart, prefs.QuietEnd = next[0], next[1]
+		case "reminders":
+			i := slices.Index(reminderPresets, prefs.ReminderInterval)
+			prefs.ReminderInterval = reminderPresets[(i+1)%len(reminderPresets)]
+		}
+
+		return nil
+	})
+	if err != nil {
+		slog.Error("error updating profile",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		answerCallback(ctx, b, query.ID, i18n.T(language, "Error saving your settings"))
+		return
+	}
+
+	answerCallback(ctx, b, query.ID, "")
+	_, err = b.EditMessageReplyMarkup(ctx, &bot.EditMessageReplyMarkupParams{
+		ChatID:      message.Chat.ID,
+		MessageID:   message.ID,
+		ReplyMarkup: h.settingsKeyboard(language, profile.Preferences),
+	})
+	if err != nil {
+		slog.Error("error updating settings menu",
+			slog.Int64("chat_id", message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func settingsText(language string) string {
+	return i18n.T(language, "Notification settings, press a button to change it.\n\n"+
+		"Use /settings quiet <HH:MM-HH:MM|off> to set other quiet hours, "+
+		"and /settings timezone <zone> (e.g. America/Montevideo) to set their time zone.")
+}
+
+// settingsKeyboard shows a button with the current value of each setting.
+func (h *BotSettingsHandler) settingsKeyboard(language string, prefs repository.Preferences) *models.InlineKeyboardMarkup {
+	onOff := func(on bool) string {
+		if on {
+			return i18n.T(language, "on")
+		}
+
+		return i18n.T(language, "off")
+	}
+
+	quiet := i18n.T(language, "off")
+	if prefs.QuietStart != "" {
+		quiet = fmt.Sprintf("%s-%s (%s)", prefs.QuietStart, prefs.QuietEnd, h.timeZone(prefs))
+	}
+
+	reminders := i18n.T(language, "every check")
+	if prefs.ReminderInterval > 0 {
+		reminders = i18n.T(language, "every %s", shortDuration(prefs.ReminderInterval))
+	}
+
+	button := func(text, data string) []models.InlineKeyboardButton {
+		return []models.InlineKeyboardButton{{Text: text, CallbackData: SettingsCallbackPrefix + data}}
+	}
+
+	return &models.InlineKeyboardMarkup{
+		InlineKeyboard: [][]models.InlineKeyboardButton{
+			button(i18n.T(language, "Sound: %s", onOff(!prefs.Silent)), "sound"),
+			button(i18n.T(language, "Screenshots: %s", onOff(!prefs.HideScreenshots)), "screenshots"),
+			button(i18n.T(language, "Quiet hours: %s", quiet), "quiet"),
+			button(i18n.T(language, "Reminders: %s", reminders), "reminders"),
+		},
+	}
+}
+
+func (h *BotSettingsHandler) timeZone(prefs repository.Preferences) string {
+	if prefs.TimeZone != "" {
+		return prefs.TimeZone
+	}
+
+	return h.config.Current().Telegram.DefaultTimeZone
+}
+
+// setPreference sets the preference from its /settings argument, the errors are meant to be translated.
+func setPreference(prefs *repository.Preferences, setting, value string) error {
+	switch setting {
+	case "quiet":
+		if strings.EqualFold(value, "off") {
+			prefs.QuietStart, prefs.QuietEnd = "", ""
+			return nil
+		}
+
+		start, end, _ := strings.Cut(value, "-")
+		if start == "" {
+			return repository.ErrInvalidQuietHours
+		}
+
+		if err := (repository.Preferences{QuietStart: start, QuietEnd: end}).Validate(); err != nil {
+			return err
+		}
+
+		prefs.QuietStart, prefs.QuietEnd = start, end
+
+	case "timezone":
+		if value == "" {
+			return repository.ErrInvalidTimeZone
+		}
+
+		if err := (repository.Preferences{TimeZone: value}).Validate(); err != nil {
+			return err
+		}
+
+		prefs.TimeZone = value
+	}
+
+	return nil
+}
+
+// inQuietHours reports whether the time is inside the subscriber quiet hours.
+func inQuietHours(prefs repository.Preferences, now time.Time, defaultTimeZone string) bool {
+	if prefs.QuietStart == "" {
+		return false
+	}
+
+	start, err := repository.ParseClock(prefs.QuietStart)
+	if err != nil {
+		return false
+	}
+
+	end, err := repository.ParseClock(prefs.QuietEnd)
+	if err != nil {
+		return false
+	}
+
+	timeZone := prefs.TimeZone
+	if timeZone == "" {
+		timeZone = defaultTimeZone
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	now = now.In(location)
+	minute := now.Hour()*60 + now.Minute()
+
+	// The quiet hours may end the next day (e.g. 23:00-07:00).
+	if start <= end {
+		return minute >= start && minute < end
+	}
+
+	return minute >= start || minute < end
+}
+
+// shortDuration formats the duration without the zero units, e.g. 1h instead of 1h0m0s.
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+
+	return s
+}
@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestInQuietHours(t *testing.T) {
+	// 12:30 UTC is 09:30 in Montevideo (UTC-3).
+	noon := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		prefs repository.Preferences
+		now   time.Time
+		want  bool
+	}{
+		{"no quiet hours", repository.Preferences{}, noon, false},
+		{"inside", repository.Preferences{QuietStart: "09:00", QuietEnd: "10:00"}, noon, true},
+		{"before", repository.Preferences{QuietStart: "10:00", QuietEnd: "11:00"}, noon, false},
+		{"start is quiet", repository.Preferences{QuietStart: "09:30", QuietEnd: "10:00"}, noon, true},
+		{"end is not quiet", repository.Preferences{QuietStart: "09:00", QuietEnd: "09:30"}, noon, false},
+		{"overnight, before midnight", repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"},
+			time.Date(2026, 10, 2, 2, 30, 0, 0, time.UTC), true},
+		{"overnight, after midnight", repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"},
+			time.Date(2026, 10, 2, 8, 0, 0, 0, time.UTC), true},
+		{"overnight, daytime", repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"}, noon, false},
+		{"subscriber time zone", repository.Preferences{QuietStart: "12:00", QuietEnd: "13:00", TimeZone: "UTC"}, noon, true},
+		{"invalid time zone", repository.Preferences{QuietStart: "12:00", QuietEnd: "13:00", TimeZone: "Nowhere"}, noon, true},
+		{"invalid quiet hours", repository.Preferences{QuietStart: "9am", QuietEnd: "10:00"}, noon, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inQuietHours(tt.prefs, tt.now, "America/Montevideo"); got != tt.want {
+				t.Errorf("inQuietHours() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetPreference(t *testing.T) {
+	quiet := repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"}
+
+	tests := []struct {
+		name    string
+		prefs   repository.Preferences
+		setting string
+		value   string
+		want    repository.Preferences
+		wantErr bool
+	}{
+		{name: "quiet hours", setting: "quiet", value: "22:30-06:00",
+			want: repository.Preferences{QuietStart: "22:30", QuietEnd: "06:00"}},
+		{name: "quiet hours off", prefs: quiet, setting: "quiet", value: "OFF"},
+		{name: "invalid quiet start", prefs: quiet, setting: "quiet", value: "25:00-07:00", want: quiet, wantErr: true},
+		{name: "missing quiet end", prefs: quiet, setting: "quiet", value: "22:00", want: quiet, wantErr: true},
+		{name: "time zone", setting: "timezone", value: "Europe/Madrid",
+			want: repository.Preferences{TimeZone: "Europe/Madrid"}},
+		{name: "unknown time zone", setting: "timezone", value: "Mars/Olympus", wantErr: true},
+		{name: "local time zone", setting: "timezone", value: "Local", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefs := tt.prefs
+			err := setPreference(&prefs, tt.setting, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %t", err, tt.wantErr)
+			}
+
+			if prefs != tt.want {
+				t.Errorf("preferences = %+v, want %+v", prefs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package notification
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/stats"
+)
+
+// statsTopEntries is how many weekdays and hours /stats shows as the best times to check.
+const statsTopEntries = 3
+
+// BotStatsHandler tells the users when the hours are usually available.
+type BotStatsHandler struct {
+	db     repository.Repository
+	config *config.Store
+}
+
+func NewBotStatsHandler(db repository.Repository, config *config.Store) *BotStatsHandler {
+	return &BotStatsHandler{
+		db:     db,
+		config: config,
+	}
+}
+
+// Stats replies the availability statistics of each target.
+func (h *BotStatsHandler) Stats(ctx context.Context, b *bot.Bot, update *models.Update) {
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
+	cfg := h.config.Current()
+
+	location, err := time.LoadLocation(cfg.Telegram.DefaultTimeZone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	now := time.Now()
+	since := now.Add(-stats.DefaultPeriod)
+
+	results, err := h.db.ResultsSince(since)
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error getting results",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(language, "Error getting the statistics"))
+		return
+	}
+
+	report := stats.Compute(results, since, now, location)
+	if len(report.Targets) == 0 {
+		sendMessage(ctx, b, chatID, i18n.T(language, "There are no checks in the last %d days yet.", days(stats.DefaultPeriod)))
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(language, "Availability in the last %d days (%s time):", days(stats.DefaultPeriod), report.TimeZone))
+	for _, target := range report.Targets {
+		name := target.Target
+		if t, ok := cfg.Target(target.Target); ok && t.Description != "" {
+			name = i18n.T(language, t.Description)
+		}
+
+		message.WriteString("\n\n")
+		message.WriteString(name)
+		message.WriteString("\n")
+		message.WriteString(i18n.T(language, "Availability windows: %d", target.Windows))
+
+		if target.Errors > 0 {
+			message.WriteString("\n")
+			message.WriteString(i18n.T(language, "Failed checks: %d", target.Errors))
+		}
+
+		if target.Windows == 0 {
+			continue
+		}
+
+		if target.AverageWindowSeconds > 0 {
+			message.WriteString("\n")
+			message.WriteString(i18n.T(language, "Average window duration: %s", roundedDuration(target.AverageWindow())))
+		}
+
+		message.WriteString("\n")
+		if sinceLast, _ := target.SinceLastAvailable(); target.Available {
+			message.WriteString(i18n.T(language, "Hours are available right now!"))
+		} else {
+			message.WriteString(i18n.T(language, "Last availability: %s ago", roundedDuration(sinceLast)))
+		}
+
+		var weekdays []string
+		for _, weekday := range topEntries(target.WindowsByWeekday) {
+			weekdays = append(weekdays, fmt.Sprintf("%s (%d)", i18n.T(language, weekday), target.WindowsByWeekday[weekday]))
+		}
+
+		hourCounts := make(map[int]int)
+		for hour, count := range target.WindowsByHour {
+			hourCounts[hour] = count
+		}
+
+		var hours []string
+		for _, hour := range topEntries(hourCounts) {
+			hours = append(hours, fmt.Sprintf("%02d:00 (%d)", hour, hourCounts[hour]))
+		}
+
+		message.WriteString("\n")
+		message.WriteString(i18n.T(language, "Most windows started on: %s", strings.Join(weekdays, ", ")))
+		message.WriteString("\n")
+		message.WriteString(i18n.T(language, "Most windows started at: %s", strings.Join(hours, ", ")))
+	}
+
+	sendMessage(ctx, b, chatID, message.String())
+}
+
+// topEntries returns the keys with the highest non-zero counts, up to statsTopEntries.
+func topEntries[K cmp.Ordered](counts map[K]int) []K {
+	var keys []K
+	for key, count := range counts {
+		if count > 0 {
+			keys = append(keys, key)
+		}
+	}
+
+	slices.SortFunc(keys, func(a, b K) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+
+		return cmp.Compare(a, b)
+	})
+
+	return keys[:min(len(keys), statsTopEntries)]
+}
+
+// roundedDuration rounds the duration to the minute, or to the second if it's shorter.
+func roundedDuration(d time.Duration) string {
+	if d < time.Minute {
+		return d.Round(time.Second).String()
+	}
+
+	return shortDuration(d.Round(time.Minute))
+}
+
+func days(d time.Duration) int {
+	return int(d / (24 * time.Hour))
+}
@@ -2,25 +2,28 @@ package notification
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
 	"github.com/skryde/booking-check/server/internal/repository"
 )
 
 type BotSubscriptionHandler struct {
-	db repository.Repository
-
-	telegramBotOwner int64
+	db     repository.Repository
+	config *config.Store
 }
 
-func NewBotSubscriptionHandler(db repository.Repository, telegramBotOwner int64) *BotSubscriptionHandler {
+func NewBotSubscriptionHandler(db repository.Repository, config *config.Store) *BotSubscriptionHandler {
 	return &BotSubscriptionHandler{
-		db:               db,
-		telegramBotOwner: telegramBotOwner,
+		db:     db,
+		config: config,
 	}
 }
 
@@ -29,41 +32,49 @@ func (s *BotSubscriptionHandler) Start(ctx context.Context, b *bot.Bot, update *
 
 Use /subscribe command to subscribe to the hour availability notification.
 Use /unsubscribe command to stop receiving notifications.
+Use /language command to change the language.
 `
-	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   message,
-	})
-	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
-	}
+	sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), message))
 }
 
+// Subscribe expects the optional "target" argument, which restricts the notifications to that target. Without it
+// the user is notified about all of them.
 func (s *BotSubscriptionHandler) Subscribe(ctx context.Context, b *bot.Bot, update *models.Update) {
-	messageText := `User subscribed`
+	language := telegrambot.Language(ctx)
+	chatID := update.Message.Chat.ID
 
-	err := s.db.AddSubscriber(update.Message.Chat.ID)
-	if err != nil {
-		slog.Error("error adding subscriber to DB",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
-		messageText = "Error subscribing to the notifications"
+	target := telegrambot.Args(ctx).String("target")
+	if cfg := s.config.Current(); target != "" {
+		if _, ok := cfg.Target(target); !ok {
+			sendMessage(ctx, b, chatID, i18n.T(language, "Sorry, %s.", i18n.T(language,
+				"unknown target '%s', it must be one of: %s", target, strings.Join(cfg.TargetNames(), ", "),
+			)))
+			return
+		}
+	}
+
+	messageText := i18n.T(language, "User subscribed")
+	if target != "" {
+		messageText = i18n.T(language, "User subscribed to the '%s' target", target)
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   messageText,
-	})
+	err := s.db.AddSubscriber(chatID)
 	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
+		telegrambot.Logger(ctx).Error("error adding subscriber to DB",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		messageText = i18n.T(language, "Error subscribing to the notifications")
+	} else if err := subscribe(s.db, chatID, target, languageCode(update), time.Now()); err != nil {
+		telegrambot.Logger(ctx).Error("error confirming subscription",
+			slog.Int64("chat_id", chatID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 	}
+
+	sendMessage(ctx, b, chatID, messageText)
 }
 
 func (s *BotSubscriptionHandler) Unsubscribe(ctx context.Context, b *bot.Bot, update *models.Update) {
@@ -71,23 +82,15 @@ func (s *BotSubscriptionHandler) Unsubscribe(ctx context.Context, b *bot.Bot, up
 
 	err := s.db.RemoveSubscriber(update.Message.Chat.ID)
 	if err != nil {
-		slog.Error("error removing subscriber from DB",
+		telegrambot.Logger(ctx).Error("error removing subscriber from DB",
 			slog.Int64("chat_id", update.Message.Chat.ID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 		messageText = "Error unsubscribing to the notifications"
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   messageText,
-	})
-	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
-	}
+	sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), messageText))
 }
 
 //
@@ -95,69 +98,38 @@ func (s *BotSubscriptionHandler) Unsubscribe(ctx context.Context, b *bot.Bot, up
 //
 
 func (s *BotSubscriptionHandler) EnableDebug(ctx context.Context, b *bot.Bot, update *models.Update) {
-	// Ignore the command if the user is not the Bot Owner.
-	if update.Message.Chat.ID != s.telegramBotOwner {
-		return
-	}
-
 	messageText := `Debug enabled`
 
 	err := s.db.ManageDebug(true)
 	if err != nil {
-		slog.Error("error enabling debug",
+		telegrambot.Logger(ctx).Error("error enabling debug",
 			slog.Int64("chat_id", update.Message.Chat.ID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 		messageText = "Error enabling debug"
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   messageText,
-	})
-	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
-	}
+	sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), messageText))
 }
 
 func (s *BotSubscriptionHandler) DisableDebug(ctx context.Context, b *bot.Bot, update *models.Update) {
-	// Ignore the command if the user is not the Bot Owner.
-	if update.Message.Chat.ID != s.telegramBotOwner {
-		return
-	}
-
 	messageText := `Debug disabled`
 
 	err := s.db.ManageDebug(false)
 	if err != nil {
-		slog.Error("error disabling debug",
+		telegrambot.Logger(ctx).Error("error disabling debug",
 			slog.Int64("chat_id", update.Message.Chat.ID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 		messageText = "Error disabling debug"
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   messageText,
-	})
-	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
-	}
+	sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), messageText))
 }
 
 func (s *BotSubscriptionHandler) Status(ctx context.Context, b *bot.Bot, update *models.Update) {
-	// Ignore the command if the user is not the Bot Owner.
-	if update.Message.Chat.ID != s.telegramBotOwner {
-		return
-	}
-
 	messageTemplate := `System status:
 
 Debug status: %t
@@ -166,30 +138,32 @@ Subscriptions: %+v
 
 	debugEnabled, err := s.db.DebugEnabled()
 	if err != nil {
-		slog.Error("error getting debug status",
+		telegrambot.Logger(ctx).Error("error getting debug status",
 			slog.Int64("chat_id", update.Message.Chat.ID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 		messageTemplate = "Error getting debug status"
 	}
 
 	subs, err := s.db.Subscribers()
 	if err != nil {
-		slog.Error("error getting subscribers",
+		telegrambot.Logger(ctx).Error("error getting subscribers",
 			slog.Int64("chat_id", update.Message.Chat.ID),
 			slog.Any("error", err),
 		)
+		telegrambot.CommandFailed(ctx, err)
 		messageTemplate = "Error getting subscribers"
 	}
 
-	_, err = b.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: update.Message.Chat.ID,
-		Text:   fmt.Sprintf(messageTemplate, debugEnabled, subs),
-	})
-	if err != nil {
-		slog.Error("error sending message",
-			slog.Int64("chat_id", update.Message.Chat.ID),
-			slog.Any("error", err),
-		)
+	sendMessage(ctx, b, update.Message.Chat.ID, i18n.T(telegrambot.Language(ctx), messageTemplate, debugEnabled, subs))
+}
+
+// languageCode returns the Telegram language_code of the message sender, empty if unknown.
+func languageCode(update *models.Update) string {
+	if update.Message.From == nil {
+		return ""
 	}
+
+	return update.Message.From.LanguageCode
 }
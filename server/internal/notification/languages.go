@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// AutoLanguage is the /language argument that removes the user choice, going back to their Telegram language.
+const AutoLanguage = "auto"
+
+// Languages picks the language of each user: the one chosen with /language, the one of their Telegram client
+// or the configured default one, in that order.
+type Languages struct {
+	db     repository.Repository
+	config *config.Store
+}
+
+func NewLanguages(db repository.Repository, config *config.Store) *Languages {
+	return &Languages{
+		db:     db,
+		config: config,
+	}
+}
+
+// Language implements telegrambot.LanguageResolver. The supported Telegram languages of the subscribers are saved
+// in their profile, so the notifications sent later are in the same language. Without a language code, for the
+// messages sent outside of an update, the profile is only read.
+func (l *Languages) Language(userID int64, languageCode string) string {
+	profile, err := l.db.Profile(userID)
+	if err != nil {
+		slog.Error("error getting profile",
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		return l.fallback(languageCode)
+	}
+
+	if i18n.Supported(profile.Language) {
+		return profile.Language
+	}
+
+	if language := i18n.Match(languageCode); language != "" && language != profile.TelegramLanguage {
+		// The rest of the users don't get notifications, a profile would only grow the database.
+		if l.subscribed(userID) {
+			_, err := l.db.UpdateProfile(userID, func(profile *repository.Profile) error {
+				profile.TelegramLanguage = language
+				return nil
+			})
+			if err != nil {
+				slog.Error("error updating profile",
+					slog.Int64("user_id", userID),
+					slog.Any("error", err),
+				)
+			}
+		}
+
+		return language
+	}
+
+	if i18n.Supported(profile.TelegramLanguage) {
+		return profile.TelegramLanguage
+	}
+
+	return l.fallback(languageCode)
+}
+
+// DefaultLanguage implements telegrambot.LanguageResolver.
+func (l *Languages) DefaultLanguage() string {
+	return l.config.Current().Telegram.DefaultLanguage
+}
+
+// Set saves the user language choice, AutoLanguage removes it.
+func (l *Languages) Set(userID int64, language string) error {
+	if language == AutoLanguage {
+		language = ""
+	}
+
+	_, err := l.db.UpdateProfile(userID, func(profile *repository.Profile) error {
+		profile.Language = language
+		return nil
+	})
+
+	return err
+}
+
+// subscribed reports whether the user is a subscriber, false if it can't be checked.
+func (l *Languages) subscribed(userID int64) bool {
+	subscribers, err := l.db.Subscribers()
+	if err != nil {
+		slog.Error("error getting subscribers",
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		return false
+	}
+
+	return slices.Contains(subscribers, userID)
+}
+
+func (l *Languages) fallback(languageCode string) string {
+	if language := i18n.Match(languageCode); language != "" {
+		return language
+	}
+
+	return l.DefaultLanguage()
+}
+
+type BotLanguageHandler struct {
+	languages *Languages
+}
+
+func NewBotLanguageHandler(languages *Languages) *BotLanguageHandler {
+	return &BotLanguageHandler{
+		languages: languages,
+	}
+}
+
+// Language expects the optional "language" argument, without it replies the current language.
+func (l *BotLanguageHandler) Language(ctx context.Context, b *bot.Bot, update *models.Update) {
+	args := telegrambot.Args(ctx)
+	if !args.Has("language") {
+		messageText := i18n.T(telegrambot.Language(ctx), "Your language is '%s', use /language <%s> to change it.",
+			telegrambot.Language(ctx), strings.Join(LanguageChoices(), "|"),
+		)
+		sendMessage(ctx, b, update.Message.Chat.ID, messageText)
+		return
+	}
+
+	// The choice is saved for the sender, whose language is resolved by their user ID also in groups.
+	chatID := update.Message.Chat.ID
+	userID := chatID
+	if update.Message.From != nil {
+		userID = update.Message.From.ID
+	}
+
+	err := l.languages.Set(userID, args.String("language"))
+	if err != nil {
+		telegrambot.Logger(ctx).Error("error setting language",
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		sendMessage(ctx, b, chatID, i18n.T(telegrambot.Language(ctx), "Error setting the language"))
+		return
+	}
+
+	language := l.languages.Language(userID, "")
+	sendMessage(ctx, b, chatID, i18n.T(language, "Language set to '%s'", language))
+}
+
+// LanguageChoices lists the valid /language arguments.
+func LanguageChoices() []string {
+	return append(i18n.Languages(), AutoLanguage)
+}
@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestLanguagesLanguage(t *testing.T) {
+	db := memory.NewDB()
+	if err := db.AddSubscriber(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Telegram.DefaultLanguage = "es"
+	languages := NewLanguages(db, config.NewStore(nil, cfg))
+
+	tests := []struct {
+		name         string
+		userID       int64
+		languageCode string
+		want         string
+		wantProfile  repository.Profile
+	}{
+		{"unknown language", 1, "", "es", repository.Profile{}},
+		{"subscriber language", 1, "en-US", "en", repository.Profile{TelegramLanguage: "en"}},
+		{"saved subscriber language", 1, "", "en", repository.Profile{TelegramLanguage: "en"}},
+		{"unsupported language", 1, "fr", "en", repository.Profile{TelegramLanguage: "en"}},
+		{"user language", 2, "en", "en", repository.Profile{}},
+		{"unsaved user language", 2, "", "es", repository.Profile{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := languages.Language(tt.userID, tt.languageCode); got != tt.want {
+				t.Errorf("Language() = %q, want %q", got, tt.want)
+			}
+
+			profile, err := db.Profile(tt.userID)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if profile != tt.wantProfile {
+				t.Errorf("profile = %+v, want %+v", profile, tt.wantProfile)
+			}
+		})
+	}
+}
+
+func TestLanguagesSet(t *testing.T) {
+	db := memory.NewDB()
+	languages := NewLanguages(db, config.NewStore(nil, config.Default()))
+
+	if err := languages.Set(1, "en"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := languages.Language(1, "es"); got != "en" {
+		t.Errorf("Language() = %q, want the chosen one", got)
+	}
+
+	if err := languages.Set(1, AutoLanguage); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := languages.Language(1, "es"); got != "es" {
+		t.Errorf("Language() = %q, want the Telegram one", got)
+	}
+}
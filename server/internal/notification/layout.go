@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/platform/imaging"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// layoutChangeThreshold is the Hamming distance between two consecutive screenshot hashes from which the
+// page is considered to have changed its layout.
+const layoutChangeThreshold = 12
+
+// checkLayout stores the screenshot hash in the target history and reports whether it differs enough from the
+// previous one to consider that the page layout changed, which usually means the scrapper selectors are broken.
+func (q *QueueHandler) checkLayout(target string, screenshot []byte) (changed bool, distance int, err error) {
+	hash, err := imaging.DifferenceHash(screenshot)
+	if err != nil {
+		return false, 0, fmt.Errorf("error hashing screenshot: %w", err)
+	}
+
+	// The history is read in the same transaction, so each concurrent result is compared with the previous one.
+	history, err := q.db.AddScreenshotHash(target, repository.ScreenshotHash{Hash: hash, CreatedAt: time.Now()})
+	if err != nil {
+		return false, 0, fmt.Errorf("error adding screenshot hash: %w", err)
+	}
+
+	if len(history) == 0 {
+		return false, 0, nil
+	}
+
+	distance = imaging.Distance(history[len(history)-1].Hash, hash)
+	return distance >= layoutChangeThreshold, distance, nil
+}
@@ -2,58 +2,96 @@ package notification
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"time"
 
 	"github.com/nats-io/nats.go"
 
+	"github.com/skryde/booking-check/server/internal/access"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
 	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
 	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/screenshot"
 )
 
 const (
 	ScrapperResultTopicName = "scrapper.result"
 	NotifierTopicName       = "notify"
+
+	// DefaultTarget is the target assumed for the scrapper results that don't specify one.
+	DefaultTarget = "default"
 )
 
 type Publisher interface {
 	Publish(string, []byte) error
 }
 
+// ObjectStore holds the payloads too big to travel inside the messages, which carry a reference instead.
+type ObjectStore interface {
+	PutObject(ctx context.Context, name string, data []byte) error
+	GetObject(ctx context.Context, name string) ([]byte, error)
+}
+
 type QueueHandler struct {
 	ctx context.Context
 
-	bot *telegrambot.TelegramBot
-	db  repository.Repository
+	bot     *telegrambot.TelegramBot
+	db      repository.Repository
+	archive *screenshot.Archive
 
 	publisher Publisher
+	objects   ObjectStore
 
-	telegramBotOwner int64
+	config     *config.Store
+	access     *access.Policy
+	languages  *Languages
+	broadcasts *BotBroadcastHandler
 }
 
 func NewQueueHandler(
 	ctx context.Context,
 	bot *telegrambot.TelegramBot,
 	db repository.Repository,
+	archive *screenshot.Archive,
 	publisher Publisher,
-	telegramBotOwner int64,
+	objects ObjectStore,
+	config *config.Store,
+	access *access.Policy,
+	languages *Languages,
+	broadcasts *BotBroadcastHandler,
 ) *QueueHandler {
 	return &QueueHandler{
-		ctx:              ctx,
-		bot:              bot,
-		db:               db,
-		publisher:        publisher,
-		telegramBotOwner: telegramBotOwner,
+		ctx:        ctx,
+		bot:        bot,
+		db:         db,
+		archive:    archive,
+		publisher:  publisher,
+		objects:    objects,
+		config:     config,
+		access:     access,
+		languages:  languages,
+		broadcasts: broadcasts,
 	}
 }
 
 func (q *QueueHandler) ScrapperResultTopic(m *nats.Msg) {
 	var payload struct {
-		Debug   bool   `json:"debug"`
-		Message string `json:"message"`
-		Image   string `json:"image"`
+		Target string `json:"target"`
+		Debug  bool   `json:"debug"`
+		// Status is empty for the scrappers that don't report it.
+		Status  repository.ResultStatus `json:"status"`
+		Message string                  `json:"message"`
+		// Image is the base64 encoded screenshot, prefer ImageRef for big images.
+		Image string `json:"image"`
+		// ImageRef is the name of the screenshot in the object store.
+		ImageRef string `json:"image_ref"`
 	}
 
 	err := json.Unmarshal(m.Data, &payload)
@@ -62,6 +100,24 @@ func (q *QueueHandler) ScrapperResultTopic(m *nats.Msg) {
 		return
 	}
 
+	if payload.Target == "" {
+		payload.Target = DefaultTarget
+	}
+
+	if payload.Status != "" && !slices.Contains(repository.ResultStatuses, payload.Status) {
+		slog.Warn("unknown scrapper result status", slog.String("status", string(payload.Status)))
+		payload.Status = ""
+	}
+
+	screenshot, image := q.resolveScreenshot(payload.Target, payload.Image, payload.ImageRef)
+	resultID := q.recordResult(repository.Result{
+		Target:    payload.Target,
+		Debug:     payload.Debug,
+		Status:    payload.Status,
+		Message:   payload.Message,
+		CreatedAt: time.Now(),
+	}, screenshot, image)
+
 	if payload.Debug {
 		debugEnabled, err := q.db.DebugEnabled()
 		if err != nil {
@@ -75,21 +131,9 @@ func (q *QueueHandler) ScrapperResultTopic(m *nats.Msg) {
 			return
 		}
 
-		err = q.publish(
-			q.telegramBotOwner,
-			payload.Message,
-			payload.Image,
-		)
-		if err != nil {
-			slog.Error("error publishing message",
-				slog.String("destiny_topic", NotifierTopicName),
-				slog.Int64("recipient", q.telegramBotOwner),
-				slog.String("message", payload.Message),
-				slog.Any("error", err),
-			)
-			return
-		}
-
+		q.notifyOwners(func(language string) string {
+			return i18n.T(language, payload.Message)
+		}, image)
 		return
 	}
 
@@ -99,76 +143,251 @@ func (q *QueueHandler) ScrapperResultTopic(m *nats.Msg) {
 		return
 	}
 
+	cfg := q.config.Current()
+	target, _ := cfg.Target(payload.Target)
+	now := time.Now()
+
 	for _, subscriber := range subs {
-		err := q.publish(subscriber, payload.Message, payload.Image)
+		// Without profile the notification is sent with the default preferences.
+		profile, profileErr := q.db.Profile(subscriber)
+		if profileErr != nil {
+			slog.Error("error getting profile",
+				slog.Int64("recipient", subscriber),
+				slog.Any("error", profileErr),
+			)
+		}
+
+		if profile.Target != "" && profile.Target != payload.Target {
+			continue
+		}
+
+		prefs := profile.Preferences
+		if prefs.ReminderInterval > 0 && now.Sub(profile.LastNotifiedAt) < prefs.ReminderInterval {
+			continue
+		}
+
+		language := q.languages.Language(subscriber, "")
+		message := render(i18n.T(language, cfg.Templates.Availability), i18n.T(language, payload.Message), map[string]any{
+			"Target":            payload.Target,
+			"TargetDescription": i18n.T(language, target.Description),
+			"Message":           i18n.T(language, payload.Message),
+		})
+
+		notification := notifyPayload{
+			Recipient:   subscriber,
+			Message:     message,
+			notifyImage: image,
+			Silent:      prefs.Silent || inQuietHours(prefs, now, cfg.Telegram.DefaultTimeZone),
+			ResultID:    resultID,
+		}
+		if prefs.HideScreenshots {
+			notification.notifyImage = notifyImage{}
+		}
+
+		err := publishNotification(q.publisher, notification)
 		if err != nil {
 			slog.Error("error publishing message",
 				slog.String("destiny_topic", NotifierTopicName),
 				slog.Int64("recipient", subscriber),
-				slog.String("message", payload.Message),
+				slog.String("message", message),
 				slog.Any("error", err),
 			)
 
 			continue
 		}
+
+		// Only the reminders need the last notification time, the rest of the profiles are not written.
+		if profileErr != nil || prefs.ReminderInterval == 0 {
+			continue
+		}
+
+		_, err = q.db.UpdateProfile(subscriber, func(profile *repository.Profile) error {
+			profile.LastNotifiedAt = now
+			return nil
+		})
+		if err != nil {
+			slog.Error("error updating profile",
+				slog.Int64("recipient", subscriber),
+				slog.Any("error", err),
+			)
+		}
 	}
 }
 
-func (q *QueueHandler) publish(recipient int64, message, image string) error {
-	var notification struct {
-		Recipient int64  `json:"recipient"`
-		Message   string `json:"message"`
-		Image     string `json:"image"`
+// resolveScreenshot returns the result screenshot and the image of its notifications: the reference in the object
+// store, so the notifications don't carry the image, or the inline image if it can't be stored. Both are empty if
+// there is no screenshot or it can't be resolved.
+func (q *QueueHandler) resolveScreenshot(target, image, imageRef string) ([]byte, notifyImage) {
+	if imageRef != "" {
+		screenshot, err := q.objects.GetObject(q.ctx, imageRef)
+		if err != nil {
+			slog.Error("error getting screenshot from object store",
+				slog.String("target", target),
+				slog.String("image_ref", imageRef),
+				slog.Any("error", err),
+			)
+			return nil, notifyImage{}
+		}
+
+		return screenshot, notifyImage{ImageRef: imageRef}
 	}
-	notification.Recipient = recipient
-	notification.Message = message
-	notification.Image = image
 
-	b, err := json.Marshal(notification)
+	if image == "" {
+		return nil, notifyImage{}
+	}
+
+	screenshot, err := base64.StdEncoding.DecodeString(image)
 	if err != nil {
-		return fmt.Errorf("error marshaling message: %w", err)
+		slog.Error("error decoding base64 image",
+			slog.String("target", target),
+			slog.Any("error", err),
+		)
+		return nil, notifyImage{}
 	}
 
-	err = q.publisher.Publish(NotifierTopicName, b)
+	imageRef = objectName(screenshot)
+
+	err = q.objects.PutObject(q.ctx, imageRef, screenshot)
 	if err != nil {
-		return fmt.Errorf("error publishing message: %w", err)
+		slog.Error("error putting screenshot in object store",
+			slog.String("target", target),
+			slog.Any("error", err),
+		)
+		// The notifications carry the image as the scrapper sent it, so it's still delivered.
+		return screenshot, notifyImage{Image: image}
 	}
 
-	return nil
+	return screenshot, notifyImage{ImageRef: imageRef}
 }
 
-func (q *QueueHandler) NotifyTopic(m *nats.Msg) {
-	var payload struct {
-		Recipient int64  `json:"recipient"`
-		Message   string `json:"message"`
-		Image     string `json:"image"`
+// recordResult archives the result screenshot, checks the page layout and stores the result in the history. It
+// returns the result ID, zero if it couldn't be stored.
+func (q *QueueHandler) recordResult(result repository.Result, screenshot []byte, image notifyImage) uint64 {
+	if len(screenshot) > 0 {
+		result.LayoutChanged = q.notifyLayoutChange(result.Target, screenshot, image)
+
+		var err error
+		result.ScreenshotID, err = q.archive.Store(screenshot)
+		if err != nil {
+			slog.Error("error archiving screenshot",
+				slog.String("target", result.Target),
+				slog.Any("error", err),
+			)
+		}
 	}
 
-	err := json.Unmarshal(m.Data, &payload)
+	id, err := q.db.AddResult(result)
 	if err != nil {
-		slog.Error("error unmarshalling message",
+		slog.Error("error adding result",
+			slog.String("target", result.Target),
 			slog.Any("error", err),
-			slog.String("topic_name", m.Subject),
-			slog.Int64("recipient", payload.Recipient),
-			slog.String("message", payload.Message),
 		)
-		return
 	}
 
-	err = q.bot.SendMessage(q.ctx, payload.Recipient, payload.Message)
+	return id
+}
+
+// notifyLayoutChange warns the Bot Owners when the target screenshot looks different from the previous one.
+func (q *QueueHandler) notifyLayoutChange(target string, screenshot []byte, image notifyImage) bool {
+	changed, distance, err := q.checkLayout(target, screenshot)
 	if err != nil {
-		slog.Error("error sending text message message",
+		slog.Error("error checking page layout",
+			slog.String("target", target),
 			slog.Any("error", err),
-			slog.String("topic_name", m.Subject),
-			slog.Int64("recipient", payload.Recipient),
-			slog.String("message", payload.Message),
 		)
+		return false
+	}
+
+	if !changed {
+		return false
+	}
+
+	slog.Warn("page layout changed",
+		slog.String("target", target),
+		slog.Int("distance", distance),
+	)
+
+	q.notifyOwners(func(language string) string {
+		fallback := i18n.T(language, "Page layout changed for target '%s' (distance %d/64).", target, distance)
+		return render(i18n.T(language, q.config.Current().Templates.LayoutChanged), fallback, map[string]any{
+			"Target":   target,
+			"Distance": distance,
+		})
+	}, image)
+	return true
+}
+
+// notifyOwners sends the message returned by the function for each owner language.
+func (q *QueueHandler) notifyOwners(message func(language string) string, image notifyImage) {
+	owners, err := q.access.Users(repository.RoleOwner)
+	if err != nil {
+		slog.Error("error getting owners", slog.Any("error", err))
 		return
 	}
 
-	img, err := base64.StdEncoding.DecodeString(payload.Image)
+	for _, owner := range owners {
+		message := message(q.languages.Language(owner, ""))
+		err := q.publish(owner, message, image)
+		if err != nil {
+			slog.Error("error publishing message",
+				slog.String("destiny_topic", NotifierTopicName),
+				slog.Int64("recipient", owner),
+				slog.String("message", message),
+				slog.Any("error", err),
+			)
+		}
+	}
+}
+
+func (q *QueueHandler) publish(recipient int64, message string, image notifyImage) error {
+	return publishNotification(q.publisher, notifyPayload{
+		Recipient:   recipient,
+		Message:     message,
+		notifyImage: image,
+	})
+}
+
+// notifyPayload is the NotifierTopicName message.
+type notifyPayload struct {
+	Recipient int64  `json:"recipient"`
+	Message   string `json:"message"`
+	notifyImage
+	// Silent sends the notification without sound.
+	Silent bool `json:"silent,omitempty"`
+	// BroadcastID is set for the broadcast notifications, whose delivery is reported to the broadcaster.
+	BroadcastID string `json:"broadcast_id,omitempty"`
+	// ResultID is set for the availability notifications, which ask the subscriber for the outcome.
+	ResultID uint64 `json:"result_id,omitempty"`
+}
+
+// notifyImage is the image sent after the notification message, if any.
+type notifyImage struct {
+	// Image is the base64 encoded image, used when it isn't in the object store. ImageRef is the name of the image
+	// in the object store.
+	Image    string `json:"image,omitempty"`
+	ImageRef string `json:"image_ref,omitempty"`
+}
+
+func publishNotification(publisher Publisher, payload notifyPayload) error {
+	b, err := json.Marshal(payload)
 	if err != nil {
-		slog.Error("error decoding base64 image",
+		return fmt.Errorf("error marshaling message: %w", err)
+	}
+
+	err = publisher.Publish(NotifierTopicName, b)
+	if err != nil {
+		return fmt.Errorf("error publishing message: %w", err)
+	}
+
+	return nil
+}
+
+func (q *QueueHandler) NotifyTopic(m *nats.Msg) {
+	var payload notifyPayload
+
+	err := json.Unmarshal(m.Data, &payload)
+	if err != nil {
+		slog.Error("error unmarshalling message",
 			slog.Any("error", err),
 			slog.String("topic_name", m.Subject),
 			slog.Int64("recipient", payload.Recipient),
@@ -177,14 +396,63 @@ func (q *QueueHandler) NotifyTopic(m *nats.Msg) {
 		return
 	}
 
-	err = q.bot.SendPhoto(q.ctx, payload.Recipient, img)
+	err = q.deliver(payload)
 	if err != nil {
-		slog.Error("error unmarshalling message",
+		slog.Error("error delivering notification",
 			slog.Any("error", err),
 			slog.String("topic_name", m.Subject),
 			slog.Int64("recipient", payload.Recipient),
 			slog.String("message", payload.Message),
+			slog.String("image_ref", payload.ImageRef),
 		)
-		return
 	}
+
+	if payload.BroadcastID != "" {
+		q.broadcasts.Delivered(payload.BroadcastID, err)
+	}
+}
+
+// deliver sends the notification message followed by its image, if any.
+func (q *QueueHandler) deliver(payload notifyPayload) error {
+	opts := []telegrambot.SendOption{telegrambot.Silent(payload.Silent)}
+	if payload.ResultID != 0 {
+		language := q.languages.Language(payload.Recipient, "")
+		opts = append(opts, telegrambot.ReplyMarkup(outcomeKeyboard(language, payload.ResultID)))
+	}
+
+	err := q.bot.SendMessage(q.ctx, payload.Recipient, payload.Message, opts...)
+	if err != nil {
+		return fmt.Errorf("error sending text message: %w", err)
+	}
+
+	var img []byte
+	switch {
+	case payload.ImageRef != "":
+		img, err = q.objects.GetObject(q.ctx, payload.ImageRef)
+		if err != nil {
+			return fmt.Errorf("error getting image from object store: %w", err)
+		}
+
+	case payload.Image != "":
+		img, err = base64.StdEncoding.DecodeString(payload.Image)
+		if err != nil {
+			return fmt.Errorf("error decoding base64 image: %w", err)
+		}
+
+	default:
+		return nil
+	}
+
+	err = q.bot.SendPhoto(q.ctx, payload.Recipient, img, telegrambot.Silent(payload.Silent))
+	if err != nil {
+		return fmt.Errorf("error sending photo: %w", err)
+	}
+
+	return nil
+}
+
+// objectName returns the content addressed name of the data in the object store.
+func objectName(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,80 @@
+package notification
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// objectStore is an in-memory ObjectStore whose writes fail with putErr, if set.
+type objectStore struct {
+	objects map[string][]byte
+	putErr  error
+}
+
+func (s *objectStore) PutObject(_ context.Context, name string, data []byte) error {
+	if s.putErr != nil {
+		return s.putErr
+	}
+
+	s.objects[name] = data
+	return nil
+}
+
+func (s *objectStore) GetObject(_ context.Context, name string) ([]byte, error) {
+	data, ok := s.objects[name]
+	if !ok {
+		return nil, errors.New("object not found")
+	}
+
+	return data, nil
+}
+
+func TestResolveScreenshot(t *testing.T) {
+	screenshot := []byte("screenshot")
+	image := base64.StdEncoding.EncodeToString(screenshot)
+
+	tests := []struct {
+		name   string
+		putErr error
+		want   notifyImage
+	}{
+		{"stored", nil, notifyImage{ImageRef: objectName(screenshot)}},
+		{"object store unavailable", errors.New("connection refused"), notifyImage{Image: image}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &QueueHandler{
+				ctx:     context.Background(),
+				objects: &objectStore{objects: map[string][]byte{}, putErr: tt.putErr},
+			}
+
+			got, img := q.resolveScreenshot(DefaultTarget, image, "")
+			if string(got) != string(screenshot) {
+				t.Errorf("screenshot = %q, want %q", got, screenshot)
+			}
+
+			if img != tt.want {
+				t.Errorf("image = %+v, want %+v", img, tt.want)
+			}
+
+			// The image must reach the notifications whether or not it was stored.
+			b, err := json.Marshal(notifyPayload{Recipient: 1, notifyImage: img})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var payload notifyPayload
+			if err := json.Unmarshal(b, &payload); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if payload.notifyImage != tt.want {
+				t.Errorf("published image = %+v, want %+v", payload.notifyImage, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/audit"
+	"github.com/skryde/booking-check/server/internal/config"
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// SubscriptionCallbackPrefix prefixes the callback data of the subscription confirmation buttons.
+const SubscriptionCallbackPrefix = "subscription:"
+
+// SubscriptionExpiry asks the subscribers whether they are still looking for an appointment once their
+// subscription is older than the configured TTL, and unsubscribes the ones that don't answer in time.
+type SubscriptionExpiry struct {
+	bot       *telegrambot.TelegramBot
+	db        repository.Repository
+	config    *config.Store
+	languages *Languages
+	audit     *audit.Log
+}
+
+func NewSubscriptionExpiry(
+	bot *telegrambot.TelegramBot,
+	db repository.Repository,
+	config *config.Store,
+	languages *Languages,
+	audit *audit.Log,
+) *SubscriptionExpiry {
+	return &SubscriptionExpiry{
+		bot:       bot,
+		db:        db,
+		config:    config,
+		languages: languages,
+		audit:     audit,
+	}
+}
+
+// Run checks the subscriptions every configured schedule until the context is done.
+func (e *SubscriptionExpiry) Run(ctx context.Context) {
+	for {
+		if err := e.Check(ctx); err != nil {
+			slog.Error("error checking subscriptions expiration", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.config.Current().Subscriptions.CheckSchedule):
+		}
+	}
+}
+
+// Check asks the confirmation to the expired subscriptions and removes the ones not confirmed in the grace period.
+func (e *SubscriptionExpiry) Check(ctx context.Context) error {
+	cfg := e.config.Current().Subscriptions
+	if cfg.TTL == 0 {
+		return nil
+	}
+
+	subs, err := e.db.Subscribers()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, subscriber := range subs {
+		// The state is changed along with the read, so the confirmations received meanwhile are not lost. The
+		// subscriber is asked or expired once the change is stored.
+		var then func()
+		_, err := e.db.UpdateProfile(subscriber, func(profile *repository.Profile) error {
+			then = nil
+
+			switch {
+			// Subscribed before the expiration was enabled, their TTL starts now.
+			case profile.ConfirmedAt.IsZero():
+				profile.ConfirmedAt = now
+
+			case profile.ConfirmationAskedAt.IsZero() && now.Sub(profile.ConfirmedAt) >= cfg.TTL:
+				profile.ConfirmationAskedAt = now
+				then = func() {
+					// The subscribers that can't be asked, e.g. because they blocked the bot, expire as well.
+					if err := e.ask(ctx, subscriber); err != nil {
+						slog.Error("error asking subscription confirmation",
+							slog.Int64("subscriber", subscriber),
+							slog.Any("error", err),
+						)
+					}
+				}
+
+			case !profile.ConfirmationAskedAt.IsZero() && now.Sub(profile.ConfirmationAskedAt) >= cfg.GracePeriod:
+				profile.ConfirmationAskedAt = time.Time{}
+				then = func() { e.expire(ctx, subscriber) }
+			}
+
+			return nil
+		})
+		if err != nil {
+			slog.Error("error updating profile",
+				slog.Int64("subscriber", subscriber),
+				slog.Any("error", err),
+			)
+			continue
+		}
+
+		if then != nil {
+			then()
+		}
+	}
+
+	return nil
+}
+
+func (e *SubscriptionExpiry) ask(ctx context.Context, subscriber int64) error {
+	language := e.languages.Language(subscriber, "")
+	grace := shortDuration(e.config.Current().Subscriptions.GracePeriod)
+
+	return e.bot.SendMessage(ctx, subscriber,
+		i18n.T(language, "Are you still looking for an appointment? If you don't answer within %s you will be unsubscribed.", grace),
+		telegrambot.ReplyMarkup(&models.InlineKeyboardMarkup{
+			InlineKeyboard: [][]models.InlineKeyboardButton{{
+				{Text: i18n.T(language, "Yes, keep notifying me"), CallbackData: SubscriptionCallbackPrefix + "confirm"},
+				{Text: i18n.T(language, "No, unsubscribe me"), CallbackData: SubscriptionCallbackPrefix + "unsubscribe"},
+			}},
+		}),
+	)
+}
+
+func (e *SubscriptionExpiry) expire(ctx context.Context, subscriber int64) {
+	err := e.db.RemoveSubscriber(subscriber)
+	e.audit.Record(repository.AuditActorSystem, SubscriptionCallbackPrefix+"expire", strconv.FormatInt(subscriber, 10), err)
+
+	if err != nil {
+		slog.Error("error removing expired subscriber",
+			slog.Int64("subscriber", subscriber),
+			slog.Any("error", err),
+		)
+		return
+	}
+
+	slog.Info("subscription expired", slog.Int64("subscriber", subscriber))
+
+	language := e.languages.Language(subscriber, "")
+	err = e.bot.SendMessage(ctx, subscriber,
+		i18n.T(language, "You were unsubscribed because you didn't confirm your subscription. Use /subscribe to subscribe again."),
+	)
+	if err != nil {
+		slog.Error("error sending subscription expiration",
+			slog.Int64("subscriber", subscriber),
+			slog.Any("error", err),
+		)
+	}
+}
+
+// Callback handles the confirmation buttons.
+func (e *SubscriptionExpiry) Callback(ctx context.Context, b *bot.Bot, update *models.Update) {
+	query := update.CallbackQuery
+	language := telegrambot.Language(ctx)
+
+	message := query.Message.Message
+	if message == nil {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "This menu is no longer available"))
+		return
+	}
+
+	chatID := message.Chat.ID
+	subs, err := e.db.Subscribers()
+	if err != nil || !slices.Contains(subs, chatID) {
+		answerCallback(ctx, b, query.ID, i18n.T(language, "You are not subscribed, use /subscribe to subscribe again."))
+		return
+	}
+
+	answerCallback(ctx, b, query.ID, "")
+	removeKeyboard(ctx, b, message)
+
+	if strings.TrimPrefix(query.Data, SubscriptionCallbackPrefix) != "confirm" {
+		messageText := i18n.T(language, "User unsubscribed")
+		err := e.db.RemoveSubscriber(chatID)
+		if err != nil {
+			slog.Error("error removing subscriber from DB",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+			telegrambot.CommandFailed(ctx, err)
+			messageText = i18n.T(language, "Error unsubscribing to the notifications")
+		}
+
+		sendMessage(ctx, b, chatID, messageText)
+		return
+	}
+
+	messageText := i18n.T(language, "Great, you will keep getting the notifications. Good luck!")
+	if err := confirmSubscription(e.db, chatID, time.Now()); err != nil {
+		slog.Error("error confirming subscription",
+			slog.Int64("chat_id", chatID),
+			slog.Any("error", err),
+		)
+		telegrambot.CommandFailed(ctx, err)
+		messageText = i18n.T(language, "Error confirming your subscription")
+	}
+
+	sendMessage(ctx, b, chatID, messageText)
+}
+
+// subscribe restarts the subscription TTL and sets the target the subscriber is notified about, empty for all,
+// and their Telegram language if supported.
+func subscribe(db repository.Repository, subscriber int64, target, languageCode string, now time.Time) error {
+	_, err := db.UpdateProfile(subscriber, func(profile *repository.Profile) error {
+		if language := i18n.Match(languageCode); language != "" {
+			profile.TelegramLanguage = language
+		}
+
+		profile.Target = target
+		profile.ConfirmedAt = now
+		profile.ConfirmationAskedAt = time.Time{}
+		return nil
+	})
+
+	return err
+}
+
+// confirmSubscription restarts the subscription TTL.
+func confirmSubscription(db repository.Repository, subscriber int64, now time.Time) error {
+	_, err := db.UpdateProfile(subscriber, func(profile *repository.Profile) error {
+		profile.ConfirmedAt = now
+		profile.ConfirmationAskedAt = time.Time{}
+		return nil
+	})
+
+	return err
+}
@@ -0,0 +1,24 @@
+package notification
+
+import (
+	"log/slog"
+	"strings"
+	"text/template"
+)
+
+// render executes the message template, returning the fallback message if it fails.
+func render(text, fallback string, data any) string {
+	tmpl, err := template.New("message").Parse(text)
+	if err != nil {
+		slog.Error("error parsing message template", slog.Any("error", err))
+		return fallback
+	}
+
+	var message strings.Builder
+	if err := tmpl.Execute(&message, data); err != nil {
+		slog.Error("error executing message template", slog.Any("error", err))
+		return fallback
+	}
+
+	return message.String()
+}
@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/platform/telegrambot"
+)
+
+// TestSender sends test messages, to check that a chat gets the notifications.
+type TestSender struct {
+	bot       *telegrambot.TelegramBot
+	languages *Languages
+}
+
+func NewTestSender(bot *telegrambot.TelegramBot, languages *Languages) *TestSender {
+	return &TestSender{
+		bot:       bot,
+		languages: languages,
+	}
+}
+
+// SendTest sends the test message in the chat language.
+func (s *TestSender) SendTest(ctx context.Context, chatID int64) error {
+	language := s.languages.Language(chatID, "")
+	return s.bot.SendMessage(ctx, chatID, i18n.T(language, "This is a test message, the notifications are working."))
+}
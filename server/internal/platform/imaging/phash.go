@@ -0,0 +1,78 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+
+	// Register the formats the scrapper may send.
+	_ "image/jpeg"
+	_ "image/png"
+)
+
+const (
+	hashWidth  = 9
+	hashHeight = 8
+)
+
+// DifferenceHash decodes the given image and returns its 64-bit difference hash (dHash).
+func DifferenceHash(data []byte) (uint64, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("error decoding image: %w", err)
+	}
+
+	return differenceHash(img), nil
+}
+
+// Distance returns the Hamming distance between two hashes: 0 means the images look the same, 64 means
+// they have nothing in common.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func differenceHash(img image.Image) uint64 {
+	var pixels [hashHeight][hashWidth]float64
+
+	bounds := img.Bounds()
+	for y := 0; y < hashHeight; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/hashHeight
+		y1 := max(bounds.Min.Y+(y+1)*bounds.Dy()/hashHeight, y0+1)
+
+		for x := 0; x < hashWidth; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/hashWidth
+			x1 := max(bounds.Min.X+(x+1)*bounds.Dx()/hashWidth, x0+1)
+
+			pixels[y][x] = averageLuminance(img, image.Rect(x0, y0, x1, y1).Intersect(bounds))
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < hashHeight; y++ {
+		for x := 0; x < hashWidth-1; x++ {
+			hash <<= 1
+			if pixels[y][x] < pixels[y][x+1] {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+func averageLuminance(img image.Image, area image.Rectangle) float64 {
+	if area.Empty() {
+		return 0
+	}
+
+	var sum float64
+	for y := area.Min.Y; y < area.Max.Y; y++ {
+		for x := area.Min.X; x < area.Max.X; x++ {
+			sum += float64(color.Gray16Model.Convert(img.At(x, y)).(color.Gray16).Y)
+		}
+	}
+
+	return sum / float64(area.Dx()*area.Dy())
+}
@@ -0,0 +1,127 @@
+package imaging
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// gradient returns a grayscale image whose luminance grows from left to right, or the opposite when reversed.
+func gradient(width, height int, reversed bool) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			v := uint8(x * 255 / (width - 1))
+			if reversed {
+				v = 255 - v
+			}
+
+			img.SetGray(x, y, color.Gray{Y: v})
+		}
+	}
+
+	return img
+}
+
+// stripes returns an image with vertical stripes, dark and light ones alternating every width/9 pixels.
+func stripes(width, height int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x*hashWidth/width%2 == 1 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("error encoding png: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("error encoding jpeg: %v", err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDifferenceHash(t *testing.T) {
+	// Each row of the stripes hash alternates the rising (1) and falling (0) bits: 10101010.
+	const stripesHash = 0xAAAAAAAAAAAAAAAA
+
+	tests := []struct {
+		name string
+		data []byte
+		want uint64
+	}{
+		{"uniform", encodePNG(t, image.NewGray(image.Rect(0, 0, 90, 80))), 0},
+		{"rising gradient", encodePNG(t, gradient(90, 80, false)), ^uint64(0)},
+		{"falling gradient", encodePNG(t, gradient(90, 80, true)), 0},
+		{"stripes", encodePNG(t, stripes(90, 80)), stripesHash},
+		{"bigger stripes", encodePNG(t, stripes(900, 400)), stripesHash},
+		{"jpeg stripes", encodeJPEG(t, stripes(900, 400)), stripesHash},
+		// Smaller than the hash grid, each pixel column covers 3 areas, which only differ at their edges: 00100100.
+		{"tiny gradient", encodePNG(t, gradient(3, 2, false)), 0x2424242424242424},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DifferenceHash(tt.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("DifferenceHash() = %#016x, want %#016x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDifferenceHashInvalidImage(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("not an image")} {
+		if _, err := DifferenceHash(data); err == nil {
+			t.Errorf("DifferenceHash(%q) returned no error", data)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b uint64
+		want int
+	}{
+		{"equal", 0x0123456789ABCDEF, 0x0123456789ABCDEF, 0},
+		{"one bit", 0, 1, 1},
+		{"highest bit", 0, 1 << 63, 1},
+		{"some bits", 0b1011, 0b0001, 2},
+		{"opposite", 0, ^uint64(0), 64},
+		{"symmetric", ^uint64(0), 0, 64},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Distance(tt.a, tt.b); got != tt.want {
+				t.Errorf("Distance(%#x, %#x) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
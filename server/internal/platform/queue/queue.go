@@ -1,24 +1,135 @@
 package queue
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
 	"time"
 
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/nats-io/nkeys"
 )
 
+const (
+	// ObjectStoreBucket is the JetStream object store bucket used to move big payloads (like screenshots) out
+	// of the messages.
+	ObjectStoreBucket = "objects"
+
+	objectStoreTTL     = 24 * time.Hour
+	objectStoreMaxSize = 512 << 20 // 512 MiB
+)
+
+// ErrObjectNotFound is returned when the requested object doesn't exist or already expired.
+var ErrObjectNotFound = errors.New("object not found")
+
 type Queue struct {
-	conn   *nats.Conn
-	server *server.Server
+	conn    *nats.Conn
+	server  *server.Server
+	objects jetstream.ObjectStore
 }
 
-func RunEmbeddedNATS(inProcess, enableLogging bool) (*Queue, error) {
+// Config defines how to reach the NATS server: it connects to URL when it's set, otherwise it runs an embedded
+// server. Auth and TLS settings apply to both the embedded server and the client connection.
+type Config struct {
+	URL string
+
+	// Embedded server settings.
+	ListenAddress string // host:port, the NATS default when empty.
+	InProcess     bool   // Don't listen at all, only the server itself can connect.
+	StoreDir      string // JetStream storage directory, a temporary one when empty.
+	EnableLogging bool
+
+	// User and Password are ignored when NKeySeedFile is set.
+	User         string
+	Password     string
+	NKeySeedFile string
+
+	TLSCert string
+	TLSKey  string
+	TLSCA   string
+}
+
+// New connects to the configured external NATS server or runs the embedded one.
+func New(cfg Config) (*Queue, error) {
+	if cfg.URL != "" {
+		var tlsOpts []nats.Option
+		if cfg.TLSCA != "" {
+			tlsOpts = append(tlsOpts, nats.RootCAs(cfg.TLSCA))
+		}
+
+		if cfg.TLSCert != "" {
+			tlsOpts = append(tlsOpts, nats.ClientCert(cfg.TLSCert, cfg.TLSKey))
+		}
+
+		natsConn, err := connect(cfg.URL, cfg, tlsOpts...)
+		if err != nil {
+			return nil, err
+		}
+
+		return newQueue(natsConn, nil)
+	}
+
+	return runEmbeddedNATS(cfg)
+}
+
+func runEmbeddedNATS(cfg Config) (*Queue, error) {
 	opts := &server.Options{
 		ServerName: "embedded_server",
-		DontListen: inProcess,
-		//JetStream:       true,
-		//JetStreamDomain: "embedded",
+		DontListen: cfg.InProcess,
+		JetStream:  true,
+		StoreDir:   cfg.StoreDir,
+	}
+
+	if cfg.ListenAddress != "" {
+		host, port, err := net.SplitHostPort(cfg.ListenAddress)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen address '%s': %w", cfg.ListenAddress, err)
+		}
+
+		opts.Host = host
+		opts.Port, err = strconv.Atoi(port)
+		if err != nil {
+			return nil, fmt.Errorf("invalid listen address '%s' port: %w", cfg.ListenAddress, err)
+		}
+	}
+
+	switch {
+	case cfg.NKeySeedFile != "":
+		publicKey, err := nkeyPublicKey(cfg.NKeySeedFile)
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Nkeys = []*server.NkeyUser{{Nkey: publicKey}}
+
+	case cfg.User != "":
+		opts.Username = cfg.User
+		opts.Password = cfg.Password
+	}
+
+	if cfg.TLSCert != "" {
+		tlsConfig, err := server.GenTLSConfig(&server.TLSConfigOpts{
+			CertFile: cfg.TLSCert,
+			KeyFile:  cfg.TLSKey,
+			CaFile:   cfg.TLSCA,
+			Verify:   cfg.TLSCA != "",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS configuration: %w", err)
+		}
+
+		opts.TLS = true
+		opts.TLSConfig = tlsConfig
+		opts.TLSVerify = cfg.TLSCA != ""
 	}
 
 	natsServer, err := server.NewServer(opts)
@@ -26,26 +137,177 @@ func RunEmbeddedNATS(inProcess, enableLogging bool) (*Queue, error) {
 		return nil, err
 	}
 
-	if enableLogging {
+	if cfg.EnableLogging {
 		natsServer.ConfigureLogger()
 	}
 
 	go natsServer.Start()
 	if !natsServer.ReadyForConnections(5 * time.Second) {
+		natsServer.Shutdown()
 		return nil, fmt.Errorf("server not ready: timeout")
 	}
 
 	var clientOpts []nats.Option
-	if inProcess {
+	if cfg.InProcess {
 		clientOpts = append(clientOpts, nats.InProcessServer(natsServer))
 	}
 
-	natsConn, err := nats.Connect(natsServer.ClientURL(), clientOpts...)
+	if cfg.TLSCert != "" {
+		tlsConfig, err := embeddedClientTLS(cfg)
+		if err != nil {
+			natsServer.Shutdown()
+			return nil, err
+		}
+
+		clientOpts = append(clientOpts, nats.Secure(tlsConfig))
+	}
+
+	natsConn, err := connect(natsServer.ClientURL(), cfg, clientOpts...)
+	if err != nil {
+		natsServer.Shutdown()
+		return nil, err
+	}
+
+	q, err := newQueue(natsConn, natsServer)
 	if err != nil {
+		natsServer.Shutdown()
 		return nil, err
 	}
 
-	return &Queue{conn: natsConn, server: natsServer}, nil
+	return q, nil
+}
+
+func connect(url string, cfg Config, opts ...nats.Option) (*nats.Conn, error) {
+	opts = append(opts,
+		nats.Name("booking-check-server"),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			slog.Warn("disconnected from NATS server", slog.Any("error", err))
+		}),
+		nats.ReconnectHandler(func(c *nats.Conn) {
+			slog.Info("reconnected to NATS server", slog.String("url", c.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(c *nats.Conn) {
+			slog.Info("NATS connection closed", slog.Any("error", c.LastError()))
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, sub *nats.Subscription, err error) {
+			subject := ""
+			if sub != nil {
+				subject = sub.Subject
+			}
+
+			slog.Error("NATS asynchronous error", slog.String("subject", subject), slog.Any("error", err))
+		}),
+	)
+
+	switch {
+	case cfg.NKeySeedFile != "":
+		nkeyOpt, err := nats.NkeyOptionFromSeed(cfg.NKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NKey seed file: %w", err)
+		}
+
+		opts = append(opts, nkeyOpt)
+
+	case cfg.User != "":
+		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
+	}
+
+	natsConn, err := nats.Connect(url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS server: %w", err)
+	}
+
+	return natsConn, nil
+}
+
+// embeddedClientTLS returns the TLS configuration of the connection to the embedded server. The server certificate
+// is trusted along with the CA, so self-signed ones work too, and its name is verified instead of the local address
+// the client dials. The same certificate authenticates the client when the server verifies them.
+func embeddedClientTLS(cfg Config) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS certificate: %w", err)
+	}
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS certificate: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+
+	if cfg.TLSCA != "" {
+		ca, err := os.ReadFile(cfg.TLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA: %w", err)
+		}
+
+		if !roots.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("invalid TLS CA '%s': no certificates found", cfg.TLSCA)
+		}
+	}
+
+	// Without DNS names the certificate must include the dialed IP address.
+	var serverName string
+	if len(leaf.DNSNames) > 0 {
+		serverName = leaf.DNSNames[0]
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func newQueue(natsConn *nats.Conn, natsServer *server.Server) (*Queue, error) {
+	objects, err := createObjectStore(natsConn)
+	if err != nil {
+		natsConn.Close()
+		return nil, err
+	}
+
+	return &Queue{conn: natsConn, server: natsServer, objects: objects}, nil
+}
+
+func nkeyPublicKey(seedFile string) (string, error) {
+	seed, err := os.ReadFile(seedFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read NKey seed file: %w", err)
+	}
+
+	keyPair, err := nkeys.FromSeed(bytes.TrimSpace(seed))
+	if err != nil {
+		return "", fmt.Errorf("invalid NKey seed: %w", err)
+	}
+
+	return keyPair.PublicKey()
+}
+
+func createObjectStore(natsConn *nats.Conn) (jetstream.ObjectStore, error) {
+	js, err := jetstream.New(natsConn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	objects, err := js.CreateOrUpdateObjectStore(ctx, jetstream.ObjectStoreConfig{
+		Bucket:      ObjectStoreBucket,
+		Description: "Big payloads referenced from the messages",
+		TTL:         objectStoreTTL,
+		MaxBytes:    objectStoreMaxSize,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create object store '%s': %w", ObjectStoreBucket, err)
+	}
+
+	return objects, nil
 }
 
 func (q *Queue) Subscribe(topicName string, handler nats.MsgHandler) error {
@@ -61,10 +323,39 @@ func (q *Queue) Publish(topicName string, data []byte) error {
 	return q.conn.Publish(topicName, data)
 }
 
+// PutObject stores the data in the object store, it can be retrieved with GetObject until it expires.
+func (q *Queue) PutObject(ctx context.Context, name string, data []byte) error {
+	_, err := q.objects.PutBytes(ctx, name, data)
+	if err != nil {
+		return fmt.Errorf("failed to put object '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+func (q *Queue) GetObject(ctx context.Context, name string) ([]byte, error) {
+	data, err := q.objects.GetBytes(ctx, name)
+	if errors.Is(err, jetstream.ErrObjectNotFound) {
+		return nil, ErrObjectNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object '%s': %w", name, err)
+	}
+
+	return data, nil
+}
+
 func (q *Queue) Shutdown() {
-	q.server.Shutdown()
+	q.conn.Close()
+
+	if q.server != nil {
+		q.server.Shutdown()
+	}
 }
 
 func (q *Queue) WaitForShutdown() {
-	q.server.WaitForShutdown()
+	if q.server != nil {
+		q.server.WaitForShutdown()
+	}
 }
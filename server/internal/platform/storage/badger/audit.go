@@ -0,0 +1,144 @@
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var (
+	auditLastIDKey = TableKey("audit_last_id")
+	auditPrefix    = TableKey("audit/")
+)
+
+func auditKey(id uint64) TableKey {
+	// Zero padded so the keys are sorted by ID.
+	return TableKey(fmt.Sprintf("%s%020d", auditPrefix, id))
+}
+
+func (d *DB) AddAuditEntry(entry repository.AuditEntry) (uint64, error) {
+	err := d.update(func(tx *badger.Txn) error {
+		var lastID uint64
+		if _, err := getJSON(tx, auditLastIDKey, &lastID); err != nil {
+			return err
+		}
+
+		entry.ID = lastID + 1
+		if err := setJSON(tx, auditLastIDKey, entry.ID); err != nil {
+			return err
+		}
+
+		return setJSON(tx, auditKey(entry.ID), entry)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error adding audit entry: %w", err)
+	}
+
+	return entry.ID, nil
+}
+
+func (d *DB) AuditEntries(filter repository.AuditFilter) ([]repository.AuditEntry, error) {
+	entries := make([]repository.AuditEntry, 0)
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = auditPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(auditKey(math.MaxUint64)); it.Valid(); it.Next() {
+			var entry repository.AuditEntry
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &entry) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			// The IDs follow the creation order, the rest of the entries are older.
+			if entry.CreatedAt.Before(filter.Since) {
+				break
+			}
+
+			if !filter.Matches(entry) {
+				continue
+			}
+
+			entries = append(entries, entry)
+			if len(entries) == filter.Limit {
+				break
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (d *DB) DeleteAuditEntriesBefore(before time.Time) (int, error) {
+	deleted := 0
+
+	for {
+		var keys [][]byte
+
+		err := d.update(func(tx *badger.Txn) error {
+			var err error
+			keys, err = expiredAuditKeys(tx, before)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if err := tx.Delete(key); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("error deleting audit entries before %s: %w", before, err)
+		}
+
+		deleted += len(keys)
+		if len(keys) < maxDeletesPerTxn {
+			return deleted, nil
+		}
+	}
+}
+
+// expiredAuditKeys returns up to maxDeletesPerTxn keys of the audit entries created before the given time.
+func expiredAuditKeys(tx *badger.Txn, before time.Time) ([][]byte, error) {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = auditPrefix
+
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	var keys [][]byte
+	for it.Rewind(); it.Valid() && len(keys) < maxDeletesPerTxn; it.Next() {
+		var entry repository.AuditEntry
+		err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &entry) })
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+		}
+
+		// The IDs follow the creation order, the rest of the entries are newer.
+		if !entry.CreatedAt.Before(before) {
+			break
+		}
+
+		keys = append(keys, it.Item().KeyCopy(nil))
+	}
+
+	return keys, nil
+}
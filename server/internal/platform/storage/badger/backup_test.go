@@ -0,0 +1,82 @@
+package badger
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestIncrementalBackup(t *testing.T) {
+	db, err := NewDB(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.AddSubscriber(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var full bytes.Buffer
+	since, err := db.Backup(&full, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var empty bytes.Buffer
+	next, err := db.Backup(&empty, since)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if next != since {
+		t.Errorf("version without changes = %d, want %d", next, since)
+	}
+
+	if err := db.AddSubscriber(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var incremental bytes.Buffer
+	if _, err := db.Backup(&incremental, since); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		backups []*bytes.Buffer
+		want    []int64
+	}{
+		{"full", []*bytes.Buffer{&full}, []int64{1}},
+		{"without changes", []*bytes.Buffer{&empty}, nil},
+		{"incremental", []*bytes.Buffer{&full, &empty, &incremental}, []int64{1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "db")
+			for i, backup := range tt.backups {
+				if err := Restore(path, bytes.NewReader(backup.Bytes()), i > 0, Options{LowMemory: true}); err != nil {
+					t.Fatalf("error restoring backup %d: %v", i, err)
+				}
+			}
+
+			restored, err := NewDB(path, Options{})
+			if err != nil {
+				t.Fatalf("error opening restored database: %v", err)
+			}
+			defer restored.Close()
+
+			subscribers, err := restored.Subscribers()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			slices.Sort(subscribers)
+			if !slices.Equal(subscribers, tt.want) {
+				t.Errorf("subscribers = %v, want %v", subscribers, tt.want)
+			}
+		})
+	}
+}
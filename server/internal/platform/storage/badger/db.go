@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"time"
 
 	"github.com/dgraph-io/badger/v4"
 )
@@ -40,22 +42,56 @@ var (
 	subscriptionsProdKey = TableKey("subscriptions_prod")
 )
 
-func NewDB(dbPath string) (*DB, error) {
+func NewDB(dbPath string, opts Options) (*DB, error) {
 	// It will be created if it doesn't exist.
-	db, err := badger.Open(badger.DefaultOptions(dbPath))
+	db, err := badger.Open(opts.badgerOptions(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	return &DB{db: db}, nil
+	d := &DB{db: db}
+	if err := d.migrate(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to migrate database: %w", err), db.Close())
+	}
+
+	return d, nil
 }
 
 func (d *DB) Close() error {
 	return errors.Join(d.db.Sync(), d.db.Close())
 }
 
+const (
+	// maxUpdateAttempts is how many times update runs a transaction that keeps conflicting before giving up.
+	maxUpdateAttempts = 20
+	// minUpdateBackoff and maxUpdateBackoff bound the random wait before retrying a conflicting transaction, which
+	// doubles with each attempt.
+	minUpdateBackoff = time.Millisecond
+	maxUpdateBackoff = 100 * time.Millisecond
+)
+
+// update runs f in a read-write transaction, retrying it when a concurrent transaction committed a change to the
+// keys it read. The retries wait a random, growing backoff so the conflicting transactions don't keep colliding,
+// and the conflict is returned after maxUpdateAttempts.
+func (d *DB) update(f func(tx *badger.Txn) error) error {
+	backoff := minUpdateBackoff
+	for attempt := 1; ; attempt++ {
+		err := d.db.Update(f)
+		if !errors.Is(err, badger.ErrConflict) {
+			return err
+		}
+
+		if attempt == maxUpdateAttempts {
+			return fmt.Errorf("transaction conflicted %d times: %w", attempt, err)
+		}
+
+		time.Sleep(rand.N(backoff) + 1)
+		backoff = min(2*backoff, maxUpdateBackoff)
+	}
+}
+
 func (d *DB) AddSubscriber(id int64) error {
-	err := d.db.Update(func(tx *badger.Txn) error {
+	err := d.update(func(tx *badger.Txn) error {
 		item, err := tx.Get(subscriptionsProdKey)
 		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
 			return fmt.Errorf("error getting subscriptions: %w", err)
@@ -99,7 +135,7 @@ func (d *DB) AddSubscriber(id int64) error {
 }
 
 func (d *DB) RemoveSubscriber(id int64) error {
-	err := d.db.Update(func(tx *badger.Txn) error {
+	err := d.update(func(tx *badger.Txn) error {
 		item, err := tx.Get(subscriptionsProdKey)
 		if err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
 			return fmt.Errorf("error getting subscriptions: %w", err)
@@ -170,7 +206,7 @@ func (d *DB) Subscribers() ([]int64, error) {
 }
 
 func (d *DB) ManageDebug(enable bool) error {
-	err := d.db.Update(func(tx *badger.Txn) error {
+	err := d.update(func(tx *badger.Txn) error {
 		newItemValue, err := json.Marshal(enable)
 		if err != nil {
 			return fmt.Errorf("error marshalling new item: %w", err)
@@ -217,3 +253,40 @@ func (d *DB) DebugEnabled() (bool, error) {
 
 	return debugEnabled, nil
 }
+
+// getJSON unmarshals the value stored under key into v, it returns false if the key doesn't exist.
+func getJSON(tx *badger.Txn, key TableKey, v any) (bool, error) {
+	item, err := tx.Get(key)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("error getting '%s': %w", key, err)
+	}
+
+	itemValue, err := item.ValueCopy(nil)
+	if err != nil {
+		return false, fmt.Errorf("error reading '%s' value: %w", key, err)
+	}
+
+	if err := json.Unmarshal(itemValue, v); err != nil {
+		return false, fmt.Errorf("error unmarshalling '%s': %w", key, err)
+	}
+
+	return true, nil
+}
+
+// setJSON marshals v and stores it under key.
+func setJSON(tx *badger.Txn, key TableKey, v any) error {
+	itemValue, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling '%s': %w", key, err)
+	}
+
+	if err := tx.Set(key, itemValue); err != nil {
+		return fmt.Errorf("error setting '%s': %w", key, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package badger
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+func TestUpdateConflicts(t *testing.T) {
+	db, err := NewDB(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	key := []byte("counter")
+	attempts := 0
+	err = db.update(func(tx *badger.Txn) error {
+		attempts++
+		if _, err := tx.Get(key); err != nil && !errors.Is(err, badger.ErrKeyNotFound) {
+			return err
+		}
+
+		// A concurrent transaction changes the key read, so this one conflicts on commit.
+		if err := db.db.Update(func(tx *badger.Txn) error { return tx.Set(key, []byte("other")) }); err != nil {
+			return err
+		}
+
+		return tx.Set(key, []byte("mine"))
+	})
+
+	if !errors.Is(err, badger.ErrConflict) {
+		t.Errorf("error = %v, want %v", err, badger.ErrConflict)
+	}
+
+	if attempts != maxUpdateAttempts {
+		t.Errorf("attempts = %d, want %d", attempts, maxUpdateAttempts)
+	}
+}
@@ -0,0 +1,135 @@
+package badger
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// valueLogGCDiscardRatio is the stale data ratio from which the value log files are rewritten.
+const valueLogGCDiscardRatio = 0.5
+
+// metrics are published through expvar: the database sizes, the value log GC runs, rewritten files and errors,
+// and the compaction runs and errors.
+var (
+	metrics  = expvar.NewMap("badger_db")
+	lsmSize  = new(expvar.Int)
+	vlogSize = new(expvar.Int)
+)
+
+func init() {
+	metrics.Set("lsm_size_bytes", lsmSize)
+	metrics.Set("vlog_size_bytes", vlogSize)
+}
+
+// Compact merges the LSM tree levels and rewrites the value log files with mostly stale entries, reclaiming
+// their disk space.
+func (d *DB) Compact() error {
+	if err := d.db.Flatten(runtime.NumCPU()); err != nil {
+		return fmt.Errorf("error flattening database: %w", err)
+	}
+
+	_, err := d.collectGarbage()
+	return err
+}
+
+// RunMaintenance collects the value log garbage every gcSchedule and compacts the database every
+// compactionSchedule, 0 to never compact it, until the context is done. The value log files keep the replaced
+// values until they are rewritten, and the LSM tree levels keep the replaced keys until they are merged.
+func (d *DB) RunMaintenance(ctx context.Context, gcSchedule, compactionSchedule time.Duration) {
+	d.updateSizeMetrics()
+
+	gc := time.NewTicker(gcSchedule)
+	defer gc.Stop()
+
+	// A nil channel never fires, so the compactions are disabled.
+	var compaction <-chan time.Time
+	if compactionSchedule > 0 {
+		ticker := time.NewTicker(compactionSchedule)
+		defer ticker.Stop()
+
+		compaction = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-gc.C:
+			d.runGC()
+		case <-compaction:
+			d.runCompaction()
+		}
+	}
+}
+
+// runGC collects the value log garbage, logging the outcome.
+func (d *DB) runGC() {
+	rewrites, err := d.collectGarbage()
+	if err != nil {
+		metrics.Add("gc_errors", 1)
+		slog.Error("error collecting database garbage", slog.Any("error", err))
+	}
+
+	metrics.Add("gc_runs", 1)
+	metrics.Add("gc_rewrites", int64(rewrites))
+	lsm, vlog := d.updateSizeMetrics()
+
+	if rewrites > 0 {
+		slog.Info("database garbage collected",
+			slog.Int("rewritten_files", rewrites),
+			slog.Int64("lsm_size", lsm),
+			slog.Int64("vlog_size", vlog),
+		)
+	}
+}
+
+// runCompaction compacts the database, logging the outcome.
+func (d *DB) runCompaction() {
+	metrics.Add("compaction_runs", 1)
+
+	start := time.Now()
+	if err := d.Compact(); err != nil {
+		metrics.Add("compaction_errors", 1)
+		slog.Error("error compacting database", slog.Any("error", err))
+		return
+	}
+
+	lsm, vlog := d.updateSizeMetrics()
+
+	slog.Info("database compacted",
+		slog.Duration("duration", time.Since(start)),
+		slog.Int64("lsm_size", lsm),
+		slog.Int64("vlog_size", vlog),
+	)
+}
+
+// collectGarbage rewrites the value log files until none has enough stale data, and returns how many it rewrote.
+func (d *DB) collectGarbage() (int, error) {
+	for rewrites := 0; ; rewrites++ {
+		err := d.db.RunValueLogGC(valueLogGCDiscardRatio)
+		if errors.Is(err, badger.ErrNoRewrite) {
+			return rewrites, nil
+		}
+
+		if err != nil {
+			return rewrites, fmt.Errorf("error running value log GC: %w", err)
+		}
+	}
+}
+
+// updateSizeMetrics publishes the LSM tree and value log sizes in bytes, which Badger refreshes every minute.
+func (d *DB) updateSizeMetrics() (lsm, vlog int64) {
+	lsm, vlog = d.db.Size()
+
+	lsmSize.Set(lsm)
+	vlogSize.Set(vlog)
+
+	return lsm, vlog
+}
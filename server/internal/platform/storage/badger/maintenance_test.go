@@ -0,0 +1,51 @@
+package badger
+
+import (
+	"context"
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestRunMaintenance(t *testing.T) {
+	db, err := NewDB(t.TempDir(), Options{LowMemory: true})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	if err := db.AddSubscriber(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counter := func(name string) int64 {
+		if v := metrics.Get(name); v != nil {
+			return v.(*expvar.Int).Value()
+		}
+
+		return 0
+	}
+
+	gcRuns, compactionRuns := counter("gc_runs"), counter("compaction_runs")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+
+	db.RunMaintenance(ctx, 50*time.Millisecond, 100*time.Millisecond)
+
+	if counter("gc_runs") == gcRuns {
+		t.Error("the value log garbage wasn't collected")
+	}
+
+	if counter("compaction_runs") == compactionRuns {
+		t.Error("the database wasn't compacted")
+	}
+
+	if errors := counter("compaction_errors") + counter("gc_errors"); errors > 0 {
+		t.Errorf("%d maintenance errors", errors)
+	}
+
+	if metrics.Get("lsm_size_bytes") != lsmSize || metrics.Get("vlog_size_bytes") != vlogSize {
+		t.Error("the size metrics were replaced")
+	}
+}
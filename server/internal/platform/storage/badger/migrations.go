@@ -0,0 +1,117 @@
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var schemaVersionKey = TableKey("schema_version")
+
+// migration upgrades the database schema to its version, the changes must fit in a single transaction.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *badger.Txn) error
+}
+
+// migrations are the schema upgrades, sorted by version. The applied migrations must not change, schema changes
+// need a new one.
+var migrations = []migration{
+	{
+		version: 1,
+		description: "initial schema: debug_status, subscriptions_prod, results, screenshots, roles, outcomes " +
+			"and profiles",
+		up: func(*badger.Txn) error { return nil },
+	},
+	{
+		version:     2,
+		description: "store the latest result of each target",
+		up:          migrateLastResults,
+	},
+}
+
+// SchemaVersion returns the applied schema version, 0 for the databases created before the migrations.
+func (d *DB) SchemaVersion() (int, error) {
+	var version int
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		_, err := getJSON(tx, schemaVersionKey, &version)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error on DB transaction getting schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// migrate applies the pending migrations in order, each one in its own transaction along with the new version.
+func (d *DB) migrate() error {
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := migrations[len(migrations)-1].version
+	if version > latest {
+		return fmt.Errorf("%w: version %d, supported %d", repository.ErrNewerSchema, version, latest)
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+
+		err := d.update(func(tx *badger.Txn) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+
+			return setJSON(tx, schemaVersionKey, m.version)
+		})
+		if err != nil {
+			return fmt.Errorf("error applying migration %d (%s): %w", m.version, m.description, err)
+		}
+
+		slog.Info("database migrated",
+			slog.Int("version", m.version),
+			slog.String("description", m.description),
+		)
+	}
+
+	return nil
+}
+
+// migrateLastResults stores the latest result of each target, which was looked up in the results before.
+func migrateLastResults(tx *badger.Txn) error {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = resultsPrefix
+
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	last := make(map[string]repository.Result)
+	for it.Rewind(); it.Valid(); it.Next() {
+		var result repository.Result
+		err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+		if err != nil {
+			return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+		}
+
+		// The results are sorted by ID, the latest one wins.
+		last[result.Target] = result
+	}
+
+	for target, result := range last {
+		if err := setJSON(tx, lastResultKey(target), result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
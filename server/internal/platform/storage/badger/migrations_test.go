@@ -0,0 +1,191 @@
+package badger
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// loadFixture creates a database with the raw entries of the testdata fixture, a JSON object of keys and values,
+// and returns its path.
+func loadFixture(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("error reading fixture: %v", err)
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("error parsing fixture: %v", err)
+	}
+
+	path := t.TempDir()
+
+	db, err := badger.Open(badger.DefaultOptions(path).WithLogger(nil))
+	if err != nil {
+		t.Fatalf("error opening fixture database: %v", err)
+	}
+
+	err = db.Update(func(tx *badger.Txn) error {
+		for key, value := range entries {
+			if err := tx.Set([]byte(key), value); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error loading fixture: %v", err)
+	}
+
+	if err := db.Close(); err != nil {
+		t.Fatalf("error closing fixture database: %v", err)
+	}
+
+	return path
+}
+
+func TestMigrateFixtures(t *testing.T) {
+	latest := migrations[len(migrations)-1].version
+
+	tests := []struct {
+		fixture     string
+		subscribers []int64
+		debug       bool
+		lastResults map[string]uint64
+		profiles    map[int64]repository.Profile
+	}{
+		{
+			fixture:     "legacy.json",
+			subscribers: []int64{12345678, 12345679},
+			debug:       true,
+			lastResults: map[string]uint64{"default": 2, "renewals": 3},
+		},
+		{
+			fixture:     "v1.json",
+			subscribers: []int64{12345678},
+			lastResults: map[string]uint64{"default": 2},
+			profiles: map[int64]repository.Profile{
+				12345678: {Language: "en", Preferences: repository.Preferences{Silent: true}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			path := loadFixture(t, tt.fixture)
+
+			// Opening it again must not apply the migrations twice.
+			for range 2 {
+				db, err := NewDB(path, Options{})
+				if err != nil {
+					t.Fatalf("error opening database: %v", err)
+				}
+
+				version, err := db.SchemaVersion()
+				if err != nil {
+					t.Fatalf("error getting schema version: %v", err)
+				}
+
+				if version != latest {
+					t.Errorf("schema version = %d, want %d", version, latest)
+				}
+
+				subs, err := db.Subscribers()
+				if err != nil {
+					t.Fatalf("error getting subscribers: %v", err)
+				}
+
+				slices.Sort(subs)
+				if !slices.Equal(subs, tt.subscribers) {
+					t.Errorf("subscribers = %v, want %v", subs, tt.subscribers)
+				}
+
+				debug, err := db.DebugEnabled()
+				if err != nil {
+					t.Fatalf("error getting debug status: %v", err)
+				}
+
+				if debug != tt.debug {
+					t.Errorf("debug = %t, want %t", debug, tt.debug)
+				}
+
+				for target, id := range tt.lastResults {
+					result, err := db.LastResult(target)
+					if err != nil {
+						t.Fatalf("error getting last result of '%s': %v", target, err)
+					}
+
+					if result.ID != id {
+						t.Errorf("last result of '%s' = %d, want %d", target, result.ID, id)
+					}
+				}
+
+				for userID, want := range tt.profiles {
+					profile, err := db.Profile(userID)
+					if err != nil {
+						t.Fatalf("error getting profile of %d: %v", userID, err)
+					}
+
+					if profile != want {
+						t.Errorf("profile of %d = %+v, want %+v", userID, profile, want)
+					}
+				}
+
+				if err := db.Close(); err != nil {
+					t.Fatalf("error closing database: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	db, err := NewDB(t.TempDir(), Options{})
+	if err != nil {
+		t.Fatalf("error opening database: %v", err)
+	}
+	defer db.Close()
+
+	version, err := db.SchemaVersion()
+	if err != nil {
+		t.Fatalf("error getting schema version: %v", err)
+	}
+
+	if latest := migrations[len(migrations)-1].version; version != latest {
+		t.Errorf("schema version = %d, want %d", version, latest)
+	}
+
+	if _, err := db.LastResult("default"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("last result error = %v, want %v", err, repository.ErrNotFound)
+	}
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	db, err := NewDB(loadFixture(t, "newer.json"), Options{})
+	if err == nil {
+		_ = db.Close()
+	}
+
+	if !errors.Is(err, repository.ErrNewerSchema) {
+		t.Fatalf("error = %v, want %v", err, repository.ErrNewerSchema)
+	}
+}
+
+func TestMigrationsSorted(t *testing.T) {
+	for i, m := range migrations {
+		if m.version != i+1 {
+			t.Errorf("migration %d has version %d, the versions must be consecutive from 1", i, m.version)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package badger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+)
+
+// Options tune the database.
+type Options struct {
+	// LowMemory shrinks the memtables, caches and value log files, which Badger sizes for large datasets by
+	// default, at the cost of more disk reads.
+	LowMemory bool
+}
+
+func (o Options) badgerOptions(dbPath string) badger.Options {
+	opts := badger.DefaultOptions(dbPath).WithLogger(logger{})
+	if !o.LowMemory {
+		return opts
+	}
+
+	return opts.
+		WithMemTableSize(8 << 20).
+		WithNumMemtables(2).
+		WithBaseTableSize(2 << 20).
+		WithNumLevelZeroTables(2).
+		WithNumLevelZeroTablesStall(4).
+		WithNumCompactors(2).
+		WithValueLogFileSize(16 << 20).
+		WithBlockCacheSize(8 << 20).
+		WithIndexCacheSize(4 << 20)
+}
+
+// logger sends the Badger logs to slog, its info ones are logged at debug level as they are routine ones.
+type logger struct{}
+
+func (logger) Errorf(format string, args ...any) {
+	log(slog.LevelError, format, args)
+}
+
+func (logger) Warningf(format string, args ...any) {
+	log(slog.LevelWarn, format, args)
+}
+
+func (logger) Infof(format string, args ...any) {
+	log(slog.LevelDebug, format, args)
+}
+
+func (logger) Debugf(format string, args ...any) {
+	log(slog.LevelDebug, format, args)
+}
+
+func log(level slog.Level, format string, args []any) {
+	ctx := context.Background()
+	if !slog.Default().Enabled(ctx, level) {
+		return
+	}
+
+	msg := strings.TrimSpace(fmt.Sprintf(format, args...))
+	slog.Log(ctx, level, msg, slog.String("component", "badger"))
+}
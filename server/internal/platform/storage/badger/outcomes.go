@@ -0,0 +1,59 @@
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var outcomesPrefix = TableKey("outcomes/")
+
+func outcomeKey(resultID uint64, subscriber int64) TableKey {
+	// Zero padded so the keys are sorted by result ID.
+	return TableKey(fmt.Sprintf("%s%020d/%d", outcomesPrefix, resultID, subscriber))
+}
+
+func (d *DB) SetOutcome(outcome repository.Outcome) error {
+	err := d.update(func(tx *badger.Txn) error {
+		return setJSON(tx, outcomeKey(outcome.ResultID, outcome.Subscriber), outcome)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting outcome for result [%d] and subscriber [%d]: %w",
+			outcome.ResultID, outcome.Subscriber, err,
+		)
+	}
+
+	return nil
+}
+
+func (d *DB) Outcomes() ([]repository.Outcome, error) {
+	var outcomes []repository.Outcome
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = outcomesPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var outcome repository.Outcome
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &outcome) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			outcomes = append(outcomes, outcome)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
@@ -0,0 +1,62 @@
+package badger
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func profileKey(userID int64) TableKey {
+	return TableKey("profiles/" + strconv.FormatInt(userID, 10))
+}
+
+func (d *DB) Profile(userID int64) (repository.Profile, error) {
+	var profile repository.Profile
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		_, err := getJSON(tx, profileKey(userID), &profile)
+		return err
+	})
+	if err != nil {
+		return repository.Profile{}, fmt.Errorf("error on DB transaction getting profile for user ID [%d]: %w", userID, err)
+	}
+
+	return profile, nil
+}
+
+func (d *DB) SetProfile(userID int64, profile repository.Profile) error {
+	err := d.update(func(tx *badger.Txn) error {
+		return setJSON(tx, profileKey(userID), profile)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting profile for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (d *DB) UpdateProfile(userID int64, update func(profile *repository.Profile) error) (repository.Profile, error) {
+	var profile repository.Profile
+
+	err := d.update(func(tx *badger.Txn) error {
+		profile = repository.Profile{}
+		if _, err := getJSON(tx, profileKey(userID), &profile); err != nil {
+			return err
+		}
+
+		current := profile
+		if err := update(&profile); err != nil || profile == current {
+			return err
+		}
+
+		return setJSON(tx, profileKey(userID), profile)
+	})
+	if err != nil {
+		return repository.Profile{}, fmt.Errorf("error updating profile for user ID [%d]: %w", userID, err)
+	}
+
+	return profile, nil
+}
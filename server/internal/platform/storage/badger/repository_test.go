@@ -0,0 +1,19 @@
+package badger
+
+import (
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		db, err := NewDB(t.TempDir(), Options{})
+		if err != nil {
+			t.Fatalf("error opening database: %v", err)
+		}
+
+		return db
+	})
+}
@@ -0,0 +1,277 @@
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var (
+	resultsLastIDKey = TableKey("results_last_id")
+	resultsPrefix    = TableKey("results/")
+	lastResultPrefix = TableKey("last_results/")
+)
+
+// maxDeletesPerTxn keeps the deletion transactions under the Badger transaction size limit.
+const maxDeletesPerTxn = 1000
+
+func resultKey(id uint64) TableKey {
+	// Zero padded so the keys are sorted by ID.
+	return TableKey(fmt.Sprintf("%s%020d", resultsPrefix, id))
+}
+
+func lastResultKey(target string) TableKey {
+	return TableKey(fmt.Sprintf("%s%s", lastResultPrefix, target))
+}
+
+func (d *DB) AddResult(result repository.Result) (uint64, error) {
+	err := d.update(func(tx *badger.Txn) error {
+		var lastID uint64
+		if _, err := getJSON(tx, resultsLastIDKey, &lastID); err != nil {
+			return err
+		}
+
+		result.ID = lastID + 1
+		if err := setJSON(tx, resultsLastIDKey, result.ID); err != nil {
+			return err
+		}
+
+		if err := setJSON(tx, resultKey(result.ID), result); err != nil {
+			return err
+		}
+
+		return setJSON(tx, lastResultKey(result.Target), result)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error adding result: %w", err)
+	}
+
+	return result.ID, nil
+}
+
+func (d *DB) Result(id uint64) (repository.Result, error) {
+	var result repository.Result
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		found, err := getJSON(tx, resultKey(id), &result)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return repository.ErrNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error on DB transaction getting result [%d]: %w", id, err)
+	}
+
+	return result, nil
+}
+
+func (d *DB) Results(limit int) ([]repository.Result, error) {
+	results := make([]repository.Result, 0, limit)
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = resultsPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		// Reverse iteration starts from the greatest key lower or equal than the seek one.
+		for it.Seek(slices.Concat(resultsPrefix, TableKey{0xFF})); it.Valid() && len(results) < limit; it.Next() {
+			var result repository.Result
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting results: %w", err)
+	}
+
+	return results, nil
+}
+
+func (d *DB) ResultsSince(since time.Time) ([]repository.Result, error) {
+	var results []repository.Result
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = resultsPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(slices.Concat(resultsPrefix, TableKey{0xFF})); it.Valid(); it.Next() {
+			var result repository.Result
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			// The IDs follow the creation order, the rest of the results are older.
+			if result.CreatedAt.Before(since) {
+				break
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting results since %s: %w", since, err)
+	}
+
+	return results, nil
+}
+
+func (d *DB) LastResult(target string) (repository.Result, error) {
+	var result repository.Result
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		found, err := getJSON(tx, lastResultKey(target), &result)
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return repository.ErrNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error on DB transaction getting last result of target '%s': %w", target, err)
+	}
+
+	return result, nil
+}
+
+func (d *DB) LastAvailableResult(target string) (repository.Result, error) {
+	var last repository.Result
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = resultsPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(slices.Concat(resultsPrefix, TableKey{0xFF})); it.Valid(); it.Next() {
+			var result repository.Result
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			if (target == "" || result.Target == target) && result.CheckStatus() == repository.ResultAvailable {
+				last = result
+				return nil
+			}
+		}
+
+		return repository.ErrNotFound
+	})
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error on DB transaction getting last available result of target '%s': %w", target, err)
+	}
+
+	return last, nil
+}
+
+func (d *DB) DeleteResultsBefore(before time.Time) (int, error) {
+	deleted := 0
+
+	for {
+		var keys [][]byte
+
+		err := d.update(func(tx *badger.Txn) error {
+			var err error
+			keys, err = expiredResultKeys(tx, before)
+			if err != nil {
+				return err
+			}
+
+			for _, key := range keys {
+				if err := tx.Delete(key); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("error deleting results before %s: %w", before, err)
+		}
+
+		deleted += len(keys)
+		if len(keys) < maxDeletesPerTxn {
+			return deleted, nil
+		}
+	}
+}
+
+// expiredResultKeys returns up to maxDeletesPerTxn keys of the results created before the given time, skipping
+// the latest result of each target.
+func expiredResultKeys(tx *badger.Txn, before time.Time) ([][]byte, error) {
+	latest := make(map[uint64]bool)
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = lastResultPrefix
+
+	it := tx.NewIterator(opts)
+	for it.Rewind(); it.Valid(); it.Next() {
+		var result repository.Result
+		err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+		if err != nil {
+			it.Close()
+			return nil, fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+		}
+
+		latest[result.ID] = true
+	}
+	it.Close()
+
+	opts.Prefix = resultsPrefix
+
+	it = tx.NewIterator(opts)
+	defer it.Close()
+
+	var keys [][]byte
+	for it.Rewind(); it.Valid() && len(keys) < maxDeletesPerTxn; it.Next() {
+		var result repository.Result
+		err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &result) })
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+		}
+
+		// The IDs follow the creation order, the rest of the results are newer.
+		if !result.CreatedAt.Before(before) {
+			break
+		}
+
+		if !latest[result.ID] {
+			keys = append(keys, it.Item().KeyCopy(nil))
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,88 @@
+package badger
+
+import (
+	"fmt"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var (
+	rolesKey            = TableKey("roles")
+	commandMenuChatsKey = TableKey("command_menu_chats")
+)
+
+func (d *DB) SetRole(userID int64, role repository.Role) error {
+	err := d.update(func(tx *badger.Txn) error {
+		roles := make(map[int64]repository.Role)
+		if _, err := getJSON(tx, rolesKey, &roles); err != nil {
+			return err
+		}
+
+		roles[userID] = role
+		return setJSON(tx, rolesKey, roles)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting role for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (d *DB) RemoveRole(userID int64) error {
+	err := d.update(func(tx *badger.Txn) error {
+		roles := make(map[int64]repository.Role)
+		found, err := getJSON(tx, rolesKey, &roles)
+		if err != nil || !found {
+			return err
+		}
+
+		delete(roles, userID)
+		return setJSON(tx, rolesKey, roles)
+	})
+	if err != nil {
+		return fmt.Errorf("error removing role for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (d *DB) UserRoles() (map[int64]repository.Role, error) {
+	roles := make(map[int64]repository.Role)
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		_, err := getJSON(tx, rolesKey, &roles)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (d *DB) CommandMenuChats() ([]int64, error) {
+	var chatIDs []int64
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		_, err := getJSON(tx, commandMenuChatsKey, &chatIDs)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting command menu chats: %w", err)
+	}
+
+	return chatIDs, nil
+}
+
+func (d *DB) SetCommandMenuChats(chatIDs []int64) error {
+	err := d.update(func(tx *badger.Txn) error {
+		return setJSON(tx, commandMenuChatsKey, chatIDs)
+	})
+	if err != nil {
+		return fmt.Errorf("error setting command menu chats: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package badger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// maxScreenshotHashes is the amount of hashes kept per target.
+const maxScreenshotHashes = 100
+
+func screenshotHashesKey(target string) TableKey {
+	return TableKey("screenshot_hashes/" + target)
+}
+
+func (d *DB) AddScreenshotHash(target string, hash repository.ScreenshotHash) ([]repository.ScreenshotHash, error) {
+	var previous []repository.ScreenshotHash
+
+	err := d.update(func(tx *badger.Txn) error {
+		// The transaction may be retried, previous must only keep the latest attempt.
+		previous = nil
+		if _, err := getJSON(tx, screenshotHashesKey(target), &previous); err != nil {
+			return err
+		}
+
+		hashes := append(slices.Clone(previous), hash)
+		if len(hashes) > maxScreenshotHashes {
+			hashes = hashes[len(hashes)-maxScreenshotHashes:]
+		}
+
+		return setJSON(tx, screenshotHashesKey(target), hashes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error adding screenshot hash for target '%s': %w", target, err)
+	}
+
+	return previous, nil
+}
+
+func (d *DB) ScreenshotHashes(target string) ([]repository.ScreenshotHash, error) {
+	var hashes []repository.ScreenshotHash
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		_, err := getJSON(tx, screenshotHashesKey(target), &hashes)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting screenshot hashes for target '%s': %w", target, err)
+	}
+
+	return hashes, nil
+}
+
+var screenshotsPrefix = TableKey("screenshots/")
+
+func screenshotKey(id string) TableKey {
+	return TableKey(string(screenshotsPrefix) + id)
+}
+
+func screenshotDataKey(id string) TableKey {
+	return TableKey("screenshots_data/" + id)
+}
+
+func (d *DB) PutScreenshot(id string, data []byte) error {
+	err := d.update(func(tx *badger.Txn) error {
+		found, err := getJSON(tx, screenshotKey(id), &repository.Screenshot{})
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			if err := tx.Set(screenshotDataKey(id), data); err != nil {
+				return fmt.Errorf("error setting screenshot data: %w", err)
+			}
+		}
+
+		return setJSON(tx, screenshotKey(id), repository.Screenshot{
+			ID:       id,
+			Size:     int64(len(data)),
+			StoredAt: time.Now(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("error storing screenshot '%s': %w", id, err)
+	}
+
+	return nil
+}
+
+func (d *DB) Screenshot(id string) ([]byte, error) {
+	var data []byte
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		item, err := tx.Get(screenshotDataKey(id))
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			return repository.ErrNotFound
+		}
+
+		if err != nil {
+			return fmt.Errorf("error getting screenshot data: %w", err)
+		}
+
+		data, err = item.ValueCopy(nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting screenshot '%s': %w", id, err)
+	}
+
+	return data, nil
+}
+
+func (d *DB) Screenshots() ([]repository.Screenshot, error) {
+	var screenshots []repository.Screenshot
+
+	err := d.db.View(func(tx *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = screenshotsPrefix
+
+		it := tx.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var screenshot repository.Screenshot
+			err := it.Item().Value(func(v []byte) error { return json.Unmarshal(v, &screenshot) })
+			if err != nil {
+				return fmt.Errorf("error unmarshalling '%s': %w", it.Item().Key(), err)
+			}
+
+			screenshots = append(screenshots, screenshot)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on DB transaction getting screenshots: %w", err)
+	}
+
+	return screenshots, nil
+}
+
+func (d *DB) DeleteScreenshot(id string) error {
+	err := d.update(func(tx *badger.Txn) error {
+		return errors.Join(tx.Delete(screenshotKey(id)), tx.Delete(screenshotDataKey(id)))
+	})
+	if err != nil {
+		return fmt.Errorf("error deleting screenshot '%s': %w", id, err)
+	}
+
+	return nil
+}
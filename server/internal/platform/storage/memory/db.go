@@ -0,0 +1,424 @@
+// Package memory implements a repository.Repository kept in memory, so the tests of the packages using the
+// repository don't need a database.
+package memory
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// maxScreenshotHashes is the amount of hashes kept per target.
+const maxScreenshotHashes = 100
+
+type outcomeKey struct {
+	resultID   uint64
+	subscriber int64
+}
+
+type screenshot struct {
+	repository.Screenshot
+	data []byte
+}
+
+// DB is safe for concurrent use. The stored values are copied, the callers can't modify them.
+type DB struct {
+	mu sync.RWMutex
+
+	subscribers map[int64]struct{}
+	debug       bool
+	hashes      map[string][]repository.ScreenshotHash
+	// results are sorted by ID, lastResultID is the latest assigned one.
+	results      []repository.Result
+	lastResultID uint64
+	lastResults  map[string]uint64
+	screenshots  map[string]screenshot
+	roles        map[int64]repository.Role
+	menuChats    []int64
+	outcomes     map[outcomeKey]repository.Outcome
+	profiles     map[int64]repository.Profile
+	// audit is sorted by ID, lastAuditID is the latest assigned one.
+	audit       []repository.AuditEntry
+	lastAuditID uint64
+}
+
+func NewDB() *DB {
+	return &DB{
+		subscribers: make(map[int64]struct{}),
+		hashes:      make(map[string][]repository.ScreenshotHash),
+		lastResults: make(map[string]uint64),
+		screenshots: make(map[string]screenshot),
+		roles:       make(map[int64]repository.Role),
+		outcomes:    make(map[outcomeKey]repository.Outcome),
+		profiles:    make(map[int64]repository.Profile),
+	}
+}
+
+func (d *DB) Close() error {
+	return nil
+}
+
+func (d *DB) AddSubscriber(id int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subscribers[id] = struct{}{}
+
+	return nil
+}
+
+func (d *DB) RemoveSubscriber(id int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.subscribers, id)
+
+	return nil
+}
+
+func (d *DB) Subscribers() ([]int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return slices.AppendSeq(make([]int64, 0, len(d.subscribers)), maps.Keys(d.subscribers)), nil
+}
+
+func (d *DB) ManageDebug(enable bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.debug = enable
+
+	return nil
+}
+
+func (d *DB) DebugEnabled() (bool, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.debug, nil
+}
+
+func (d *DB) AddScreenshotHash(target string, hash repository.ScreenshotHash) ([]repository.ScreenshotHash, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	previous := slices.Clone(d.hashes[target])
+
+	hashes := append(d.hashes[target], hash)
+	if len(hashes) > maxScreenshotHashes {
+		hashes = slices.Clone(hashes[len(hashes)-maxScreenshotHashes:])
+	}
+
+	d.hashes[target] = hashes
+
+	return previous, nil
+}
+
+func (d *DB) ScreenshotHashes(target string) ([]repository.ScreenshotHash, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return slices.Clone(d.hashes[target]), nil
+}
+
+func (d *DB) AddResult(result repository.Result) (uint64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lastResultID++
+	result.ID = d.lastResultID
+	d.results = append(d.results, result)
+	d.lastResults[result.Target] = result.ID
+
+	return result.ID, nil
+}
+
+func (d *DB) Result(id uint64) (repository.Result, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result, ok := d.result(id)
+	if !ok {
+		return repository.Result{}, fmt.Errorf("error getting result [%d]: %w", id, repository.ErrNotFound)
+	}
+
+	return result, nil
+}
+
+// result finds the result by ID, the caller must hold the lock.
+func (d *DB) result(id uint64) (repository.Result, bool) {
+	i, ok := slices.BinarySearchFunc(d.results, id, func(result repository.Result, id uint64) int {
+		return cmp.Compare(result.ID, id)
+	})
+	if !ok {
+		return repository.Result{}, false
+	}
+
+	return d.results[i], true
+}
+
+func (d *DB) Results(limit int) ([]repository.Result, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	results := make([]repository.Result, 0, max(0, min(limit, len(d.results))))
+	for i := len(d.results) - 1; i >= 0 && len(results) < limit; i-- {
+		results = append(results, d.results[i])
+	}
+
+	return results, nil
+}
+
+func (d *DB) ResultsSince(since time.Time) ([]repository.Result, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	results := make([]repository.Result, 0)
+	for i := len(d.results) - 1; i >= 0; i-- {
+		if d.results[i].CreatedAt.Before(since) {
+			continue
+		}
+
+		results = append(results, d.results[i])
+	}
+
+	return results, nil
+}
+
+func (d *DB) LastResult(target string) (repository.Result, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	id, ok := d.lastResults[target]
+	if !ok {
+		return repository.Result{}, fmt.Errorf("error getting last result of target '%s': %w", target, repository.ErrNotFound)
+	}
+
+	result, _ := d.result(id)
+	return result, nil
+}
+
+func (d *DB) LastAvailableResult(target string) (repository.Result, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for i := len(d.results) - 1; i >= 0; i-- {
+		result := d.results[i]
+		if (target == "" || result.Target == target) && result.CheckStatus() == repository.ResultAvailable {
+			return result, nil
+		}
+	}
+
+	return repository.Result{}, fmt.Errorf("error getting last available result of target '%s': %w", target, repository.ErrNotFound)
+}
+
+func (d *DB) DeleteResultsBefore(before time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	latest := make(map[uint64]bool, len(d.lastResults))
+	for _, id := range d.lastResults {
+		latest[id] = true
+	}
+
+	count := len(d.results)
+	d.results = slices.DeleteFunc(d.results, func(result repository.Result) bool {
+		return result.CreatedAt.Before(before) && !latest[result.ID]
+	})
+
+	return count - len(d.results), nil
+}
+
+func (d *DB) PutScreenshot(id string, data []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	// The ID is the data hash, storing it again only refreshes the StoredAt.
+	stored, ok := d.screenshots[id]
+	if !ok {
+		stored = screenshot{
+			Screenshot: repository.Screenshot{ID: id, Size: int64(len(data))},
+			data:       slices.Clone(data),
+		}
+	}
+
+	stored.StoredAt = time.Now()
+	d.screenshots[id] = stored
+
+	return nil
+}
+
+func (d *DB) Screenshot(id string) ([]byte, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	stored, ok := d.screenshots[id]
+	if !ok {
+		return nil, fmt.Errorf("error getting screenshot '%s': %w", id, repository.ErrNotFound)
+	}
+
+	return slices.Clone(stored.data), nil
+}
+
+func (d *DB) Screenshots() ([]repository.Screenshot, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	screenshots := make([]repository.Screenshot, 0, len(d.screenshots))
+	for _, stored := range d.screenshots {
+		screenshots = append(screenshots, stored.Screenshot)
+	}
+
+	slices.SortFunc(screenshots, func(a, b repository.Screenshot) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	return screenshots, nil
+}
+
+func (d *DB) DeleteScreenshot(id string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.screenshots, id)
+
+	return nil
+}
+
+func (d *DB) SetRole(userID int64, role repository.Role) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.roles[userID] = role
+
+	return nil
+}
+
+func (d *DB) RemoveRole(userID int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	delete(d.roles, userID)
+
+	return nil
+}
+
+func (d *DB) UserRoles() (map[int64]repository.Role, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return maps.Clone(d.roles), nil
+}
+
+func (d *DB) CommandMenuChats() ([]int64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return slices.Clone(d.menuChats), nil
+}
+
+func (d *DB) SetCommandMenuChats(chatIDs []int64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.menuChats = slices.Clone(chatIDs)
+
+	return nil
+}
+
+func (d *DB) SetOutcome(outcome repository.Outcome) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.outcomes[outcomeKey{resultID: outcome.ResultID, subscriber: outcome.Subscriber}] = outcome
+
+	return nil
+}
+
+func (d *DB) Outcomes() ([]repository.Outcome, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	outcomes := slices.Collect(maps.Values(d.outcomes))
+	slices.SortFunc(outcomes, func(a, b repository.Outcome) int {
+		return cmp.Or(cmp.Compare(a.ResultID, b.ResultID), cmp.Compare(a.Subscriber, b.Subscriber))
+	})
+
+	return outcomes, nil
+}
+
+func (d *DB) Profile(userID int64) (repository.Profile, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return d.profiles[userID], nil
+}
+
+func (d *DB) SetProfile(userID int64, profile repository.Profile) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.profiles[userID] = profile
+
+	return nil
+}
+
+func (d *DB) UpdateProfile(userID int64, update func(profile *repository.Profile) error) (repository.Profile, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	current, ok := d.profiles[userID]
+	profile := current
+	if err := update(&profile); err != nil {
+		return repository.Profile{}, fmt.Errorf("error updating profile for user ID [%d]: %w", userID, err)
+	}
+
+	if !ok || profile != current {
+		d.profiles[userID] = profile
+	}
+
+	return profile, nil
+}
+
+func (d *DB) AddAuditEntry(entry repository.AuditEntry) (uint64, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.lastAuditID++
+	entry.ID = d.lastAuditID
+	d.audit = append(d.audit, entry)
+
+	return entry.ID, nil
+}
+
+func (d *DB) AuditEntries(filter repository.AuditFilter) ([]repository.AuditEntry, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	entries := make([]repository.AuditEntry, 0)
+	for i := len(d.audit) - 1; i >= 0 && (filter.Limit == 0 || len(entries) < filter.Limit); i-- {
+		if filter.Matches(d.audit[i]) {
+			entries = append(entries, d.audit[i])
+		}
+	}
+
+	return entries, nil
+}
+
+func (d *DB) DeleteAuditEntriesBefore(before time.Time) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	count := len(d.audit)
+	d.audit = slices.DeleteFunc(d.audit, func(entry repository.AuditEntry) bool {
+		return entry.CreatedAt.Before(before)
+	})
+
+	return count - len(d.audit), nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(*testing.T) repository.Repository {
+		return NewDB()
+	})
+}
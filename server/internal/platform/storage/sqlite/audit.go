@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func (d *DB) AddAuditEntry(entry repository.AuditEntry) (uint64, error) {
+	res, err := d.db.Exec(`INSERT INTO audit (actor, action, target, outcome, error, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Actor, entry.Action, entry.Target, entry.Outcome, entry.Error, unixNano(entry.CreatedAt),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error adding audit entry: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting audit entry ID: %w", err)
+	}
+
+	return uint64(id), nil
+}
+
+func (d *DB) AuditEntries(filter repository.AuditFilter) ([]repository.AuditEntry, error) {
+	query := "SELECT id, actor, action, target, outcome, error, created_at FROM audit WHERE created_at >= ?"
+	args := []any{unixNano(filter.Since)}
+
+	if filter.Actor != "" {
+		query += " AND actor = ?"
+		args = append(args, filter.Actor)
+	}
+
+	if filter.Action != "" {
+		query += " AND action = ?"
+		args = append(args, filter.Action)
+	}
+
+	query += " ORDER BY id DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]repository.AuditEntry, 0)
+	for rows.Next() {
+		var (
+			entry     repository.AuditEntry
+			createdAt int64
+		)
+
+		err := rows.Scan(&entry.ID, &entry.Actor, &entry.Action, &entry.Target, &entry.Outcome, &entry.Error, &createdAt)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning audit entry: %w", err)
+		}
+
+		entry.CreatedAt = fromUnixNano(createdAt)
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (d *DB) DeleteAuditEntriesBefore(before time.Time) (int, error) {
+	// AUTOINCREMENT keeps the deleted IDs from being assigned again.
+	res, err := d.db.Exec("DELETE FROM audit WHERE created_at < ?", unixNano(before))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting audit entries before %s: %w", before, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting deleted audit entries: %w", err)
+	}
+
+	return int(deleted), nil
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Backup writes a full backup of the database, a consistent copy of its file. SQLite doesn't support incremental
+// backups, since must be 0; the returned version is always 0.
+func (d *DB) Backup(w io.Writer, since uint64) (uint64, error) {
+	if since != 0 {
+		return 0, errors.New("the SQLite database only supports full backups")
+	}
+
+	dir, err := os.MkdirTemp("", "booking-check-backup-")
+	if err != nil {
+		return 0, fmt.Errorf("error creating backup directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "backup.db")
+	if _, err := d.db.Exec("VACUUM INTO ?", path); err != nil {
+		return 0, fmt.Errorf("error backing up database: %w", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("error opening backup: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(w, file); err != nil {
+		return 0, fmt.Errorf("error writing backup: %w", err)
+	}
+
+	return 0, nil
+}
+
+// IncrementalBackups reports false, Backup only writes full backups.
+func (d *DB) IncrementalBackups() bool {
+	return false
+}
+
+// Compact rebuilds the database file, reclaiming the space of the deleted rows.
+func (d *DB) Compact() error {
+	if _, err := d.db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("error vacuuming database: %w", err)
+	}
+
+	return nil
+}
+
+// Restore writes the backup to the database file at dbPath, which must not exist, once its integrity is checked.
+// SQLite backups are always full ones, incremental must be false.
+func Restore(dbPath string, r io.Reader, incremental bool) error {
+	if incremental {
+		return errors.New("the SQLite database only supports full backups")
+	}
+
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("database file '%s' already exists", dbPath)
+	}
+
+	// The backup is written next to the database, so it can be renamed once checked.
+	file, err := os.CreateTemp(filepath.Dir(dbPath), filepath.Base(dbPath)+".restore-")
+	if err != nil {
+		return fmt.Errorf("error creating database file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, r); err != nil {
+		return errors.Join(fmt.Errorf("error writing database file: %w", err), file.Close())
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing database file: %w", err)
+	}
+
+	if err := checkIntegrity(file.Name()); err != nil {
+		return err
+	}
+
+	if err := os.Rename(file.Name(), dbPath); err != nil {
+		return fmt.Errorf("error moving restored database: %w", err)
+	}
+
+	return nil
+}
+
+func checkIntegrity(path string) error {
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open restored database: %w", err)
+	}
+	defer db.Close()
+
+	var result string
+	if err := db.QueryRow("PRAGMA integrity_check").Scan(&result); err != nil {
+		return fmt.Errorf("error verifying restored database: %w", err)
+	}
+
+	if result != "ok" {
+		return fmt.Errorf("restored database is corrupted: %s", result)
+	}
+
+	return nil
+}
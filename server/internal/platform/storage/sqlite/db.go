@@ -0,0 +1,253 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	// The pure Go driver keeps the server buildable without CGO.
+	_ "modernc.org/sqlite"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// migrations are the schema upgrades, the version of each one is its position starting at 1. The applied
+// migrations must not change, schema changes need a new one. The applied version is the database user_version.
+var migrations = []string{
+	`CREATE TABLE settings (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	);
+	CREATE TABLE subscribers (
+		id INTEGER PRIMARY KEY
+	);
+	CREATE TABLE screenshot_hashes (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		target     TEXT NOT NULL,
+		hash       INTEGER NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX screenshot_hashes_target ON screenshot_hashes (target, id);
+	CREATE TABLE results (
+		id             INTEGER PRIMARY KEY AUTOINCREMENT,
+		target         TEXT NOT NULL,
+		debug          INTEGER NOT NULL,
+		message        TEXT NOT NULL,
+		screenshot_id  TEXT NOT NULL,
+		layout_changed INTEGER NOT NULL,
+		created_at     INTEGER NOT NULL
+	);
+	CREATE INDEX results_target ON results (target, id);
+	CREATE TABLE screenshots (
+		id        TEXT PRIMARY KEY,
+		size      INTEGER NOT NULL,
+		stored_at INTEGER NOT NULL,
+		data      BLOB NOT NULL
+	);
+	CREATE TABLE roles (
+		user_id INTEGER PRIMARY KEY,
+		role    TEXT NOT NULL
+	);
+	CREATE TABLE outcomes (
+		result_id  INTEGER NOT NULL,
+		subscriber INTEGER NOT NULL,
+		status     TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		PRIMARY KEY (result_id, subscriber)
+	);
+	CREATE TABLE profiles (
+		user_id INTEGER PRIMARY KEY,
+		profile TEXT NOT NULL
+	);`,
+	`CREATE TABLE audit (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		actor      TEXT NOT NULL,
+		action     TEXT NOT NULL,
+		target     TEXT NOT NULL,
+		outcome    TEXT NOT NULL,
+		error      TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);`,
+	`ALTER TABLE results ADD COLUMN status TEXT NOT NULL DEFAULT '';`,
+}
+
+const (
+	debugStatusKey = "debug_status"
+	// commandMenuChatsKey holds the JSON list of chats with a command menu of their own.
+	commandMenuChatsKey = "command_menu_chats"
+)
+
+type DB struct {
+	db *sql.DB
+}
+
+func NewDB(dbPath string) (*DB, error) {
+	// It will be created if it doesn't exist.
+	db, err := sql.Open("sqlite", "file:"+dbPath+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// A single connection serializes the writes, which are few.
+	db.SetMaxOpenConns(1)
+
+	d := &DB{db: db}
+	if err := d.migrate(); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to migrate database: %w", err), db.Close())
+	}
+
+	return d, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// SchemaVersion returns the applied schema version.
+func (d *DB) SchemaVersion() (int, error) {
+	var version int
+	if err := d.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("error getting schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// migrate applies the pending migrations in order, each one in its own transaction along with the new version.
+func (d *DB) migrate() error {
+	version, err := d.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	if version > len(migrations) {
+		return fmt.Errorf("%w: version %d, supported %d", repository.ErrNewerSchema, version, len(migrations))
+	}
+
+	for i, statements := range migrations[version:] {
+		next := version + i + 1
+
+		err := d.update(func(tx *sql.Tx) error {
+			if _, err := tx.Exec(statements); err != nil {
+				return err
+			}
+
+			// PRAGMA doesn't support parameters.
+			_, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", next))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("error applying migration %d: %w", next, err)
+		}
+
+		slog.Info("database migrated", slog.Int("version", next))
+	}
+
+	return nil
+}
+
+// update runs f in a transaction, committed if f doesn't fail.
+func (d *DB) update(f func(tx *sql.Tx) error) error {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error beginning transaction: %w", err)
+	}
+
+	if err := f(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) AddSubscriber(id int64) error {
+	_, err := d.db.Exec("INSERT INTO subscribers (id) VALUES (?) ON CONFLICT DO NOTHING", id)
+	if err != nil {
+		return fmt.Errorf("error adding subscription for user ID [%d]: %w", id, err)
+	}
+
+	return nil
+}
+
+func (d *DB) RemoveSubscriber(id int64) error {
+	_, err := d.db.Exec("DELETE FROM subscribers WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("error removing subscription for user ID [%d]: %w", id, err)
+	}
+
+	return nil
+}
+
+func (d *DB) Subscribers() ([]int64, error) {
+	rows, err := d.db.Query("SELECT id FROM subscribers")
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscribers: %w", err)
+	}
+	defer rows.Close()
+
+	subs := make([]int64, 0)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("error scanning subscriber: %w", err)
+		}
+
+		subs = append(subs, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting subscribers: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (d *DB) ManageDebug(enable bool) error {
+	_, err := d.db.Exec(
+		"INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		debugStatusKey, fmt.Sprint(enable),
+	)
+	if err != nil {
+		return fmt.Errorf("error setting debug status: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) DebugEnabled() (bool, error) {
+	var value string
+
+	err := d.db.QueryRow("SELECT value FROM settings WHERE key = ?", debugStatusKey).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, fmt.Errorf("error getting debug status: %w", err)
+	}
+
+	return value == "true", nil
+}
+
+// unixNano stores the times as nanoseconds since the Unix epoch, 0 for the zero time.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+func fromUnixNano(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, nanos)
+}
@@ -0,0 +1,20 @@
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+	"github.com/skryde/booking-check/server/internal/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repository.Repository {
+		db, err := NewDB(filepath.Join(t.TempDir(), "booking-check.db"))
+		if err != nil {
+			t.Fatalf("error opening database: %v", err)
+		}
+
+		return db
+	})
+}
@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// maxScreenshotHashes is the amount of hashes kept per target.
+const maxScreenshotHashes = 100
+
+const resultColumns = "id, target, debug, status, message, screenshot_id, layout_changed, created_at"
+
+func (d *DB) AddScreenshotHash(target string, hash repository.ScreenshotHash) ([]repository.ScreenshotHash, error) {
+	var previous []repository.ScreenshotHash
+
+	err := d.update(func(tx *sql.Tx) error {
+		var err error
+		previous, err = queryScreenshotHashes(tx, target)
+		if err != nil {
+			return err
+		}
+
+		// The hashes are stored with the same bits, SQLite integers are signed.
+		_, err = tx.Exec("INSERT INTO screenshot_hashes (target, hash, created_at) VALUES (?, ?, ?)",
+			target, int64(hash.Hash), unixNano(hash.CreatedAt),
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`DELETE FROM screenshot_hashes WHERE target = ? AND id NOT IN (
+			SELECT id FROM screenshot_hashes WHERE target = ? ORDER BY id DESC LIMIT ?
+		)`, target, target, maxScreenshotHashes)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error adding screenshot hash for target '%s': %w", target, err)
+	}
+
+	return previous, nil
+}
+
+func (d *DB) ScreenshotHashes(target string) ([]repository.ScreenshotHash, error) {
+	return queryScreenshotHashes(d.db, target)
+}
+
+func queryScreenshotHashes(
+	db interface {
+		Query(query string, args ...any) (*sql.Rows, error)
+	},
+	target string,
+) ([]repository.ScreenshotHash, error) {
+	rows, err := db.Query("SELECT hash, created_at FROM screenshot_hashes WHERE target = ? ORDER BY id", target)
+	if err != nil {
+		return nil, fmt.Errorf("error getting screenshot hashes for target '%s': %w", target, err)
+	}
+	defer rows.Close()
+
+	var hashes []repository.ScreenshotHash
+	for rows.Next() {
+		var hash, createdAt int64
+		if err := rows.Scan(&hash, &createdAt); err != nil {
+			return nil, fmt.Errorf("error scanning screenshot hash: %w", err)
+		}
+
+		hashes = append(hashes, repository.ScreenshotHash{Hash: uint64(hash), CreatedAt: fromUnixNano(createdAt)})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting screenshot hashes for target '%s': %w", target, err)
+	}
+
+	return hashes, nil
+}
+
+func (d *DB) AddResult(result repository.Result) (uint64, error) {
+	res, err := d.db.Exec(`INSERT INTO results (target, debug, status, message, screenshot_id, layout_changed, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		result.Target, result.Debug, result.Status, result.Message, result.ScreenshotID, result.LayoutChanged,
+		unixNano(result.CreatedAt),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("error adding result: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("error getting result ID: %w", err)
+	}
+
+	return uint64(id), nil
+}
+
+func (d *DB) Result(id uint64) (repository.Result, error) {
+	result, err := scanResult(d.db.QueryRow("SELECT "+resultColumns+" FROM results WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
+
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error getting result [%d]: %w", id, err)
+	}
+
+	return result, nil
+}
+
+func (d *DB) Results(limit int) ([]repository.Result, error) {
+	results, err := d.queryResults("SELECT "+resultColumns+" FROM results ORDER BY id DESC LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("error getting results: %w", err)
+	}
+
+	return results, nil
+}
+
+func (d *DB) ResultsSince(since time.Time) ([]repository.Result, error) {
+	results, err := d.queryResults(
+		"SELECT "+resultColumns+" FROM results WHERE created_at >= ? ORDER BY id DESC", unixNano(since),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting results since %s: %w", since, err)
+	}
+
+	return results, nil
+}
+
+func (d *DB) LastResult(target string) (repository.Result, error) {
+	result, err := scanResult(d.db.QueryRow(
+		"SELECT "+resultColumns+" FROM results WHERE target = ? ORDER BY id DESC LIMIT 1", target,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
+
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error getting last result of target '%s': %w", target, err)
+	}
+
+	return result, nil
+}
+
+func (d *DB) LastAvailableResult(target string) (repository.Result, error) {
+	// The results stored without status are available unless they are debug ones, see Result.CheckStatus.
+	result, err := scanResult(d.db.QueryRow(
+		"SELECT "+resultColumns+` FROM results WHERE (? = '' OR target = ?) AND (status = ? OR (status = '' AND NOT debug))
+		ORDER BY id DESC LIMIT 1`, target, target, repository.ResultAvailable,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
+
+	if err != nil {
+		return repository.Result{}, fmt.Errorf("error getting last available result of target '%s': %w", target, err)
+	}
+
+	return result, nil
+}
+
+func (d *DB) DeleteResultsBefore(before time.Time) (int, error) {
+	res, err := d.db.Exec(`DELETE FROM results WHERE created_at < ? AND id NOT IN (
+		SELECT MAX(id) FROM results GROUP BY target
+	)`, unixNano(before))
+	if err != nil {
+		return 0, fmt.Errorf("error deleting results before %s: %w", before, err)
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error getting deleted results: %w", err)
+	}
+
+	return int(deleted), nil
+}
+
+func (d *DB) queryResults(query string, args ...any) ([]repository.Result, error) {
+	rows, err := d.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]repository.Result, 0)
+	for rows.Next() {
+		result, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+// scanResult scans the resultColumns of a sql.Row or sql.Rows.
+func scanResult(row interface{ Scan(dest ...any) error }) (repository.Result, error) {
+	var (
+		result    repository.Result
+		createdAt int64
+	)
+
+	err := row.Scan(&result.ID, &result.Target, &result.Debug, &result.Status, &result.Message, &result.ScreenshotID,
+		&result.LayoutChanged, &createdAt,
+	)
+	if err != nil {
+		return repository.Result{}, err
+	}
+
+	result.CreatedAt = fromUnixNano(createdAt)
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func (d *DB) PutScreenshot(id string, data []byte) error {
+	// The ID is the data hash, storing it again only refreshes the StoredAt.
+	_, err := d.db.Exec(`INSERT INTO screenshots (id, size, stored_at, data) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET stored_at = excluded.stored_at`,
+		id, len(data), unixNano(time.Now()), data,
+	)
+	if err != nil {
+		return fmt.Errorf("error storing screenshot '%s': %w", id, err)
+	}
+
+	return nil
+}
+
+func (d *DB) Screenshot(id string) ([]byte, error) {
+	var data []byte
+
+	err := d.db.QueryRow("SELECT data FROM screenshots WHERE id = ?", id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting screenshot '%s': %w", id, err)
+	}
+
+	return data, nil
+}
+
+func (d *DB) Screenshots() ([]repository.Screenshot, error) {
+	rows, err := d.db.Query("SELECT id, size, stored_at FROM screenshots ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("error getting screenshots: %w", err)
+	}
+	defer rows.Close()
+
+	var screenshots []repository.Screenshot
+	for rows.Next() {
+		var (
+			screenshot repository.Screenshot
+			storedAt   int64
+		)
+
+		if err := rows.Scan(&screenshot.ID, &screenshot.Size, &storedAt); err != nil {
+			return nil, fmt.Errorf("error scanning screenshot: %w", err)
+		}
+
+		screenshot.StoredAt = fromUnixNano(storedAt)
+		screenshots = append(screenshots, screenshot)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting screenshots: %w", err)
+	}
+
+	return screenshots, nil
+}
+
+func (d *DB) DeleteScreenshot(id string) error {
+	if _, err := d.db.Exec("DELETE FROM screenshots WHERE id = ?", id); err != nil {
+		return fmt.Errorf("error deleting screenshot '%s': %w", id, err)
+	}
+
+	return nil
+}
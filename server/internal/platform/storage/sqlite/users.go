@@ -0,0 +1,220 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func (d *DB) SetRole(userID int64, role repository.Role) error {
+	_, err := d.db.Exec(
+		"INSERT INTO roles (user_id, role) VALUES (?, ?) ON CONFLICT (user_id) DO UPDATE SET role = excluded.role",
+		userID, role,
+	)
+	if err != nil {
+		return fmt.Errorf("error setting role for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (d *DB) RemoveRole(userID int64) error {
+	if _, err := d.db.Exec("DELETE FROM roles WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("error removing role for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
+
+func (d *DB) UserRoles() (map[int64]repository.Role, error) {
+	rows, err := d.db.Query("SELECT user_id, role FROM roles")
+	if err != nil {
+		return nil, fmt.Errorf("error getting roles: %w", err)
+	}
+	defer rows.Close()
+
+	roles := make(map[int64]repository.Role)
+	for rows.Next() {
+		var (
+			userID int64
+			role   repository.Role
+		)
+
+		if err := rows.Scan(&userID, &role); err != nil {
+			return nil, fmt.Errorf("error scanning role: %w", err)
+		}
+
+		roles[userID] = role
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (d *DB) CommandMenuChats() ([]int64, error) {
+	var data []byte
+
+	err := d.db.QueryRow("SELECT value FROM settings WHERE key = ?", commandMenuChatsKey).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting command menu chats: %w", err)
+	}
+
+	var chatIDs []int64
+	if err := json.Unmarshal(data, &chatIDs); err != nil {
+		return nil, fmt.Errorf("error unmarshalling command menu chats: %w", err)
+	}
+
+	return chatIDs, nil
+}
+
+func (d *DB) SetCommandMenuChats(chatIDs []int64) error {
+	data, err := json.Marshal(chatIDs)
+	if err != nil {
+		return fmt.Errorf("error marshalling command menu chats: %w", err)
+	}
+
+	_, err = d.db.Exec(
+		"INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		commandMenuChatsKey, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("error setting command menu chats: %w", err)
+	}
+
+	return nil
+}
+
+func (d *DB) SetOutcome(outcome repository.Outcome) error {
+	_, err := d.db.Exec(`INSERT INTO outcomes (result_id, subscriber, status, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (result_id, subscriber) DO UPDATE SET status = excluded.status, created_at = excluded.created_at`,
+		outcome.ResultID, outcome.Subscriber, outcome.Status, unixNano(outcome.CreatedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("error setting outcome for result [%d] and subscriber [%d]: %w",
+			outcome.ResultID, outcome.Subscriber, err,
+		)
+	}
+
+	return nil
+}
+
+func (d *DB) Outcomes() ([]repository.Outcome, error) {
+	rows, err := d.db.Query("SELECT result_id, subscriber, status, created_at FROM outcomes ORDER BY result_id, subscriber")
+	if err != nil {
+		return nil, fmt.Errorf("error getting outcomes: %w", err)
+	}
+	defer rows.Close()
+
+	var outcomes []repository.Outcome
+	for rows.Next() {
+		var (
+			outcome   repository.Outcome
+			createdAt int64
+		)
+
+		if err := rows.Scan(&outcome.ResultID, &outcome.Subscriber, &outcome.Status, &createdAt); err != nil {
+			return nil, fmt.Errorf("error scanning outcome: %w", err)
+		}
+
+		outcome.CreatedAt = fromUnixNano(createdAt)
+		outcomes = append(outcomes, outcome)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error getting outcomes: %w", err)
+	}
+
+	return outcomes, nil
+}
+
+// Profile returns the zero Profile if the user doesn't have one. The profiles are stored as JSON, as they only
+// are read by user.
+func (d *DB) Profile(userID int64) (repository.Profile, error) {
+	return queryProfile(d.db, userID)
+}
+
+func (d *DB) SetProfile(userID int64, profile repository.Profile) error {
+	return execSetProfile(d.db, userID, profile)
+}
+
+func (d *DB) UpdateProfile(userID int64, update func(profile *repository.Profile) error) (repository.Profile, error) {
+	var profile repository.Profile
+
+	err := d.update(func(tx *sql.Tx) error {
+		var err error
+		profile, err = queryProfile(tx, userID)
+		if err != nil {
+			return err
+		}
+
+		current := profile
+		if err := update(&profile); err != nil || profile == current {
+			return err
+		}
+
+		return execSetProfile(tx, userID, profile)
+	})
+	if err != nil {
+		return repository.Profile{}, fmt.Errorf("error updating profile for user ID [%d]: %w", userID, err)
+	}
+
+	return profile, nil
+}
+
+func queryProfile(
+	db interface {
+		QueryRow(query string, args ...any) *sql.Row
+	},
+	userID int64,
+) (repository.Profile, error) {
+	var data []byte
+
+	err := db.QueryRow("SELECT profile FROM profiles WHERE user_id = ?", userID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.Profile{}, nil
+	}
+
+	if err != nil {
+		return repository.Profile{}, fmt.Errorf("error getting profile for user ID [%d]: %w", userID, err)
+	}
+
+	var profile repository.Profile
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return repository.Profile{}, fmt.Errorf("error unmarshalling profile for user ID [%d]: %w", userID, err)
+	}
+
+	return profile, nil
+}
+
+func execSetProfile(
+	db interface {
+		Exec(query string, args ...any) (sql.Result, error)
+	},
+	userID int64,
+	profile repository.Profile,
+) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return fmt.Errorf("error marshalling profile for user ID [%d]: %w", userID, err)
+	}
+
+	_, err = db.Exec(
+		"INSERT INTO profiles (user_id, profile) VALUES (?, ?) ON CONFLICT (user_id) DO UPDATE SET profile = excluded.profile",
+		userID, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("error setting profile for user ID [%d]: %w", userID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+var (
+	errNotAuthorized = errors.New("not authorized")
+	errRateLimited   = errors.New("rate limited")
+	errPanic         = errors.New("panic handling command")
+)
+
+// Auditor records the handled commands.
+type Auditor interface {
+	RecordCommand(ctx context.Context, record CommandRecord)
+}
+
+// CommandRecord describes a handled command: who sent it, in which chat, its arguments and how it ended. The
+// pressed buttons are recorded with their callback data as the command. Denied is set when the user was not
+// allowed to run it, and Err describes why it was denied or failed.
+type CommandRecord struct {
+	UserID  int64
+	ChatID  int64
+	Command string
+	Args    string
+	Denied  bool
+	Err     error
+}
+
+type auditKey struct{}
+
+// Audit records every command through the auditor once handled. It must run before the middlewares refusing the
+// commands, so the refused ones are recorded too.
+func Audit(auditor Auditor) Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			record := &CommandRecord{
+				UserID:  senderID(update),
+				ChatID:  chatID(update),
+				Command: CommandName(ctx),
+			}
+
+			if update.CallbackQuery != nil {
+				record.Command = update.CallbackQuery.Data
+			} else {
+				_, _, record.Args = splitCommand(messageText(update.Message))
+			}
+
+			// Recorded even if the handler panics, the Recover middleware logs the panic.
+			handled := false
+			defer func() {
+				if !handled {
+					record.Err = errPanic
+				}
+
+				auditor.RecordCommand(ctx, *record)
+			}()
+
+			next(context.WithValue(ctx, auditKey{}, record), b, update)
+			handled = true
+		}
+	}
+}
+
+// CommandFailed records the command being handled as failed with the given error, for the handlers that reply
+// the errors to the user instead of returning them.
+func CommandFailed(ctx context.Context, err error) {
+	if record, ok := ctx.Value(auditKey{}).(*CommandRecord); ok {
+		record.Err = err
+	}
+}
+
+func commandDenied(ctx context.Context, err error) {
+	if record, ok := ctx.Value(auditKey{}).(*CommandRecord); ok {
+		record.Denied = true
+		record.Err = err
+	}
+}
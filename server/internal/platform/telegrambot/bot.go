@@ -7,9 +7,14 @@ import (
 	"fmt"
 	"log/slog"
 	"regexp"
+	"slices"
+	"sync"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
 )
 
 var commandValidationPattern = regexp.MustCompile("^/[a-z]+$")
@@ -18,15 +23,37 @@ type TelegramBot struct {
 	bot *bot.Bot
 
 	myDescription string
-	myCommands    bot.SetMyCommandsParams
 
 	startHandler bot.HandlerFunc
+
+	// username is used to match the commands addressed to this bot in groups, it's resolved by Start.
+	username string
+
+	mu          sync.RWMutex
+	middlewares []Middleware
+	commands    []Command
+
+	// languages picks the language of the replies, menus and descriptions shown to each user.
+	languages LanguageResolver
+	// permissions filters the commands listed by /help and published in each chat menu.
+	permissions Permissions
+
+	// menuMu serializes RefreshCommandMenus, which owns scopedChats: the chats with a command menu of their own,
+	// kept in menuStore if set.
+	menuMu      sync.Mutex
+	scopedChats []int64
+	menuStore   MenuStore
+
+	// limiter throttles the messages sent through SendMessage and SendPhoto.
+	limiter *rate.Limiter
 }
 
 // TODO: Sanitize errors to mask the Bot API Token.
 func NewBot(token, myDescription string, startHandler bot.HandlerFunc) (*TelegramBot, error) {
 	opts := []bot.Option{
 		bot.WithDefaultHandler(func(ctx context.Context, b *bot.Bot, update *models.Update) {}),
+		// The bot user is resolved by Start, so creating the bot doesn't require the Bot API.
+		bot.WithSkipGetMe(),
 	}
 
 	b, err := bot.New(token, opts...)
@@ -37,8 +64,8 @@ func NewBot(token, myDescription string, startHandler bot.HandlerFunc) (*Telegra
 	tb := &TelegramBot{
 		bot:           b,
 		myDescription: myDescription,
-		myCommands:    bot.SetMyCommandsParams{Scope: &models.BotCommandScopeDefault{}},
 		startHandler:  startHandler,
+		limiter:       rate.NewLimiter(rate.Inf, 0),
 	}
 
 	err = tb.configure()
@@ -54,58 +81,183 @@ func (t *TelegramBot) configure() error {
 		return fmt.Errorf("error registering '%s' comand", "/me")
 	}
 
+	if err := t.RegisterCommandHandler("/help", "Lists the commands you can use", t.helpHandler); err != nil {
+		return fmt.Errorf("error registering '%s' comand", "/help")
+	}
+
 	startHandler := t.startHandler
 	if startHandler == nil {
 		startHandler = defaultStartHandler
 	}
 
-	t.bot.RegisterHandler(bot.HandlerTypeMessageText, "/start", bot.MatchTypeExact, startHandler)
+	if err := t.RegisterCommandHandler("/start", "", startHandler); err != nil {
+		return fmt.Errorf("error registering '%s' comand", "/start")
+	}
+
 	return nil
 }
 
-func (t *TelegramBot) RegisterCommandHandler(pattern, description string, handler bot.HandlerFunc) error {
-	if !commandValidationPattern.MatchString(pattern) {
+// Use adds middlewares applied to every command, before the command specific ones.
+func (t *TelegramBot) Use(middlewares ...Middleware) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.middlewares = append(t.middlewares, middlewares...)
+}
+
+// RegisterCommandHandler registers a command without arguments; the given middlewares only apply to this
+// command, after the global ones.
+func (t *TelegramBot) RegisterCommandHandler(pattern, description string, handler bot.HandlerFunc, middlewares ...Middleware) error {
+	return t.RegisterCommand(Command{
+		Pattern:     pattern,
+		Description: description,
+		Handler:     handler,
+		Middlewares: middlewares,
+	})
+}
+
+// RegisterCommand registers the command handler. If no description is provided the command is not shown in the
+// bot menu.
+func (t *TelegramBot) RegisterCommand(cmd Command) error {
+	if !commandValidationPattern.MatchString(cmd.Pattern) {
 		return errors.New("invalid command pattern: it must start with a slash and contains only minuscules letters")
 	}
 
-	// If no description is provided, assume that the dev does not want to show the command to the users.
-	if len(description) > 0 {
-		t.myCommands.Commands = append(t.myCommands.Commands, models.BotCommand{
-			Command:     pattern[1:],
-			Description: description,
-		})
+	for i, arg := range cmd.Args {
+		if arg.Type == ArgText && i != len(cmd.Args)-1 {
+			return fmt.Errorf("invalid '%s' command: text argument '%s' must be the last one", cmd.Pattern, arg.Name)
+		}
 	}
 
-	t.bot.RegisterHandler(bot.HandlerTypeMessageText, pattern, bot.MatchTypeExact, handler)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.commands = append(t.commands, cmd)
+
+	handler := chain(cmd.Handler, append([]Middleware{cmd.parseArguments}, cmd.Middlewares...)...)
+	t.bot.RegisterHandlerMatchFunc(t.matchCommand(cmd.Pattern), func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		t.mu.RLock()
+		global := chain(handler, t.middlewares...)
+		t.mu.RUnlock()
+
+		ctx = context.WithValue(ctx, commandKey{}, cmd.Pattern)
+		ctx = context.WithValue(ctx, languageKey{}, t.language(update))
+		global(ctx, b, update)
+	})
 
 	return nil
 }
 
+// RegisterCallbackHandler handles the inline keyboard buttons whose callback data starts with the prefix. The
+// global middlewares are applied with the prefix as the command name, so the buttons can be restricted like the
+// commands; the handler must still check the user can press that button and answer the callback query. The user
+// language is available through the context.
+func (t *TelegramBot) RegisterCallbackHandler(prefix string, handler bot.HandlerFunc) {
+	t.bot.RegisterHandler(bot.HandlerTypeCallbackQueryData, prefix, bot.MatchTypePrefix,
+		func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			t.mu.RLock()
+			global := chain(handler, t.middlewares...)
+			t.mu.RUnlock()
+
+			ctx = context.WithValue(ctx, commandKey{}, prefix)
+			ctx = context.WithValue(ctx, languageKey{}, t.language(update))
+			global(ctx, b, update)
+		},
+	)
+}
+
+// Commands returns the registered commands.
+func (t *TelegramBot) Commands() []Command {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return slices.Clone(t.commands)
+}
+
 func (t *TelegramBot) Start(ctx context.Context) error {
-	_, err := t.bot.SetMyDescription(ctx, &bot.SetMyDescriptionParams{
-		Description: t.myDescription,
+	me, err := t.bot.GetMe(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting telegram bot user: %w", err)
+	}
+
+	// Set before the updates are handled, which is when it's read.
+	t.username = me.Username
+
+	// The description without language code is shown to the users whose language is not supported.
+	_, err = t.bot.SetMyDescription(ctx, &bot.SetMyDescriptionParams{
+		Description: i18n.T(t.defaultLanguage(), t.myDescription),
 	})
 	if err != nil {
 		return fmt.Errorf("error setting telegram bot description: %w", err)
 	}
 
-	success, err := t.bot.SetMyCommands(ctx, &t.myCommands)
-	if err != nil {
-		return fmt.Errorf("error setting telegram bot commands: %w", err)
+	for _, language := range i18n.Languages() {
+		_, err := t.bot.SetMyDescription(ctx, &bot.SetMyDescriptionParams{
+			Description:  i18n.T(language, t.myDescription),
+			LanguageCode: language,
+		})
+		if err != nil {
+			return fmt.Errorf("error setting telegram bot '%s' description: %w", language, err)
+		}
 	}
 
-	if !success {
-		return fmt.Errorf("telegram bot commands not set")
+	err = t.RefreshCommandMenus(ctx)
+	if err != nil {
+		return err
 	}
 
 	t.bot.Start(ctx)
 	return nil
 }
 
-func (t *TelegramBot) SendMessage(ctx context.Context, recipient int64, message string) error {
+// SetRateLimit limits the messages sent per second, allowing bursts of the given size.
+func (t *TelegramBot) SetRateLimit(messagesPerSecond float64, burst int) {
+	t.limiter.SetLimit(rate.Limit(messagesPerSecond))
+	t.limiter.SetBurst(burst)
+}
+
+// SendOption customizes the messages sent through SendMessage and SendPhoto.
+type SendOption func(*sendOptions)
+
+type sendOptions struct {
+	silent      bool
+	replyMarkup models.ReplyMarkup
+}
+
+// Silent sends the message without sound, if silent is true.
+func Silent(silent bool) SendOption {
+	return func(o *sendOptions) {
+		o.silent = silent
+	}
+}
+
+// ReplyMarkup attaches the markup, e.g. an inline keyboard, to the message.
+func ReplyMarkup(markup models.ReplyMarkup) SendOption {
+	return func(o *sendOptions) {
+		o.replyMarkup = markup
+	}
+}
+
+func newSendOptions(opts []SendOption) sendOptions {
+	var options sendOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+func (t *TelegramBot) SendMessage(ctx context.Context, recipient int64, message string, opts ...SendOption) error {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("error waiting for rate limiter: %w", err)
+	}
+
+	options := newSendOptions(opts)
 	_, err := t.bot.SendMessage(ctx, &bot.SendMessageParams{
-		ChatID: recipient,
-		Text:   message,
+		ChatID:              recipient,
+		Text:                message,
+		DisableNotification: options.silent,
+		ReplyMarkup:         options.replyMarkup,
 	})
 	if err != nil {
 		slog.Error("error sending message",
@@ -118,13 +270,20 @@ func (t *TelegramBot) SendMessage(ctx context.Context, recipient int64, message
 	return nil
 }
 
-func (t *TelegramBot) SendPhoto(ctx context.Context, recipient int64, message []byte) error {
+func (t *TelegramBot) SendPhoto(ctx context.Context, recipient int64, message []byte, opts ...SendOption) error {
+	if err := t.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("error waiting for rate limiter: %w", err)
+	}
+
+	options := newSendOptions(opts)
 	_, err := t.bot.SendPhoto(ctx, &bot.SendPhotoParams{
 		ChatID: recipient,
 		Photo: &models.InputFileUpload{
 			Filename: "scrapper-screenshot",
 			Data:     bytes.NewReader(message),
 		},
+		DisableNotification: options.silent,
+		ReplyMarkup:         options.replyMarkup,
 	})
 
 	if err != nil {
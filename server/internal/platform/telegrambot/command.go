@@ -0,0 +1,218 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+)
+
+type ArgType int
+
+const (
+	// ArgString is a single word.
+	ArgString ArgType = iota
+	// ArgInt is an integer number.
+	ArgInt
+	// ArgText is the rest of the message, it must be the last argument.
+	ArgText
+)
+
+// Arg describes a command argument, Choices restricts the accepted values when it's not empty.
+type Arg struct {
+	Name     string
+	Type     ArgType
+	Optional bool
+	Choices  []string
+}
+
+// Command is a bot command registration: the handler runs after the global middlewares and the command ones,
+// with the parsed arguments available through Args.
+type Command struct {
+	Pattern     string
+	Description string
+	Args        []Arg
+	Handler     bot.HandlerFunc
+	Middlewares []Middleware
+}
+
+// Usage returns the command syntax, e.g. "/grant <user_id> <role>".
+func (c Command) Usage() string {
+	usage := c.Pattern
+	for _, arg := range c.Args {
+		name := arg.Name
+		if len(arg.Choices) > 0 {
+			name = strings.Join(arg.Choices, "|")
+		}
+
+		if arg.Type == ArgText {
+			name += "..."
+		}
+
+		if arg.Optional {
+			usage += " [" + name + "]"
+		} else {
+			usage += " <" + name + ">"
+		}
+	}
+
+	return usage
+}
+
+// Arguments are the parsed command arguments, indexed by name.
+type Arguments map[string]any
+
+type argumentsKey struct{}
+
+// Args returns the arguments of the command being handled.
+func Args(ctx context.Context) Arguments {
+	args, _ := ctx.Value(argumentsKey{}).(Arguments)
+	return args
+}
+
+// String returns the value of an ArgString or ArgText argument, empty if it wasn't given.
+func (a Arguments) String(name string) string {
+	value, _ := a[name].(string)
+	return value
+}
+
+// Int returns the value of an ArgInt argument, 0 if it wasn't given.
+func (a Arguments) Int(name string) int64 {
+	value, _ := a[name].(int64)
+	return value
+}
+
+// Has reports whether the optional argument was given.
+func (a Arguments) Has(name string) bool {
+	_, ok := a[name]
+	return ok
+}
+
+// parseArgs parses the text that follows the command.
+func (c Command) parseArgs(text, language string) (Arguments, error) {
+	args := make(Arguments, len(c.Args))
+	fields := strings.Fields(text)
+
+	for i, arg := range c.Args {
+		if i >= len(fields) {
+			if !arg.Optional {
+				return nil, errors.New(i18n.T(language, "missing <%s> argument", arg.Name))
+			}
+
+			continue
+		}
+
+		value := fields[i]
+		if arg.Type == ArgText {
+			// Keep the original text formatting, line breaks included.
+			value = skipFields(text, i)
+			fields = fields[:i+1]
+		}
+
+		if len(arg.Choices) > 0 && !slices.Contains(arg.Choices, strings.ToLower(value)) {
+			return nil, errors.New(i18n.T(language, "invalid <%s> argument '%s', it must be one of: %s",
+				arg.Name, value, strings.Join(arg.Choices, ", "),
+			))
+		}
+
+		switch arg.Type {
+		case ArgInt:
+			number, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, errors.New(i18n.T(language, "invalid <%s> argument '%s', it must be a number", arg.Name, value))
+			}
+
+			args[arg.Name] = number
+
+		case ArgString:
+			if len(arg.Choices) > 0 {
+				value = strings.ToLower(value)
+			}
+
+			args[arg.Name] = value
+
+		default:
+			args[arg.Name] = value
+		}
+	}
+
+	if len(fields) > len(c.Args) {
+		return nil, errors.New(i18n.T(language, "too many arguments"))
+	}
+
+	return args, nil
+}
+
+// skipFields returns the text without its first n whitespace separated fields.
+func skipFields(text string, n int) string {
+	for ; n > 0; n-- {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+
+		end := strings.IndexFunc(text, unicode.IsSpace)
+		if end < 0 {
+			return ""
+		}
+
+		text = text[end:]
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// splitCommand splits the message text in the command, the bot username it's addressed to (if any) and the
+// rest of the text.
+func splitCommand(text string) (command, username, rest string) {
+	if fields := strings.Fields(text); len(fields) > 0 {
+		command = fields[0]
+	}
+
+	command, username, _ = strings.Cut(command, "@")
+
+	return command, username, skipFields(text, 1)
+}
+
+// matchCommand matches the messages with the command, addressed to any bot (e.g. "/status") or to this one
+// (e.g. "/status@MyBot" in groups).
+func (t *TelegramBot) matchCommand(pattern string) bot.MatchFunc {
+	return func(update *models.Update) bool {
+		if update.Message == nil {
+			return false
+		}
+
+		command, username, _ := splitCommand(messageText(update.Message))
+		return command == pattern && (username == "" || strings.EqualFold(username, t.username))
+	}
+}
+
+// parseArguments replies the usage to the messages with invalid arguments instead of running the handler.
+func (c Command) parseArguments(next bot.HandlerFunc) bot.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+		_, _, text := splitCommand(messageText(update.Message))
+
+		args, err := c.parseArgs(text, Language(ctx))
+		if err != nil {
+			CommandFailed(ctx, err)
+			reply(ctx, b, update, i18n.T(Language(ctx), "Sorry, %s.\n\nUsage: %s", err, c.Usage()))
+			return
+		}
+
+		next(context.WithValue(ctx, argumentsKey{}, args), b, update)
+	}
+}
+
+// messageText returns the message text or, for the media messages, their caption; so commands can be sent along
+// with a photo.
+func messageText(message *models.Message) string {
+	if message.Text == "" {
+		return message.Caption
+	}
+
+	return message.Text
+}
@@ -0,0 +1,160 @@
+package telegrambot
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+)
+
+var testCommand = Command{
+	Pattern: "/grant",
+	Args: []Arg{
+		{Name: "user_id", Type: ArgInt},
+		{Name: "role", Type: ArgString, Choices: []string{"viewer", "admin"}},
+		{Name: "note", Type: ArgText, Optional: true},
+	},
+}
+
+func TestCommandUsage(t *testing.T) {
+	tests := []struct {
+		command Command
+		want    string
+	}{
+		{Command{Pattern: "/status"}, "/status"},
+		{Command{Pattern: "/subscribe", Args: []Arg{{Name: "target", Optional: true}}}, "/subscribe [target]"},
+		{testCommand, "/grant <user_id> <viewer|admin> [note...]"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.command.Usage(); got != tt.want {
+			t.Errorf("Usage() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestCommandParseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		command Command
+		text    string
+		want    Arguments
+		wantErr string
+	}{
+		{
+			name:    "no arguments",
+			command: Command{Pattern: "/status"},
+			want:    Arguments{},
+		},
+		{
+			name:    "optional argument",
+			command: Command{Pattern: "/subscribe", Args: []Arg{{Name: "target", Optional: true}}},
+			text:    "madrid",
+			want:    Arguments{"target": "madrid"},
+		},
+		{
+			name:    "missing optional argument",
+			command: Command{Pattern: "/subscribe", Args: []Arg{{Name: "target", Optional: true}}},
+			want:    Arguments{},
+		},
+		{
+			name:    "every argument",
+			command: testCommand,
+			text:    "42 Admin  keep the\nformatting ",
+			want:    Arguments{"user_id": int64(42), "role": "admin", "note": "keep the\nformatting"},
+		},
+		{
+			name:    "missing argument",
+			command: testCommand,
+			text:    "42",
+			wantErr: "missing <role> argument",
+		},
+		{
+			name:    "invalid number",
+			command: testCommand,
+			text:    "me admin",
+			wantErr: "invalid <user_id> argument 'me', it must be a number",
+		},
+		{
+			name:    "invalid choice",
+			command: testCommand,
+			text:    "42 root",
+			wantErr: "invalid <role> argument 'root', it must be one of: viewer, admin",
+		},
+		{
+			name:    "too many arguments",
+			command: Command{Pattern: "/status"},
+			text:    "now",
+			wantErr: "too many arguments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.command.parseArgs(tt.text, "en")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("error = %v, want %q", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseArgs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitCommand(t *testing.T) {
+	tests := []struct {
+		text                    string
+		command, username, rest string
+	}{
+		{"", "", "", ""},
+		{"/status", "/status", "", ""},
+		{"/subscribe madrid", "/subscribe", "", "madrid"},
+		{"/subscribe@MyBot  madrid\n", "/subscribe", "MyBot", "madrid"},
+		{"  /broadcast hello\nworld", "/broadcast", "", "hello\nworld"},
+	}
+
+	for _, tt := range tests {
+		command, username, rest := splitCommand(tt.text)
+		if command != tt.command || username != tt.username || rest != tt.rest {
+			t.Errorf("splitCommand(%q) = %q, %q, %q, want %q, %q, %q",
+				tt.text, command, username, rest, tt.command, tt.username, tt.rest)
+		}
+	}
+}
+
+func TestMatchCommand(t *testing.T) {
+	match := (&TelegramBot{username: "MyBot"}).matchCommand("/subscribe")
+
+	tests := []struct {
+		name    string
+		message *models.Message
+		want    bool
+	}{
+		{"command", &models.Message{Text: "/subscribe"}, true},
+		{"arguments", &models.Message{Text: "/subscribe madrid"}, true},
+		{"addressed to the bot", &models.Message{Text: "/subscribe@mybot madrid"}, true},
+		{"photo caption", &models.Message{Caption: "/subscribe"}, true},
+		{"addressed to another bot", &models.Message{Text: "/subscribe@OtherBot"}, false},
+		{"other command", &models.Message{Text: "/subscribers"}, false},
+		{"not a command", &models.Message{Text: "subscribe"}, false},
+		{"no message", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := match(&models.Update{Message: tt.message}); got != tt.want {
+				t.Errorf("match() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,18 +2,19 @@ package telegrambot
 
 import (
 	"context"
-	"fmt"
 	"log/slog"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
 )
 
 func meHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	message := "Your Telegram user ID is: `%d`"
+	message := i18n.T(Language(ctx), "Your Telegram user ID is: `%d`", update.Message.Chat.ID)
 	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID:    update.Message.Chat.ID,
-		Text:      fmt.Sprintf(message, update.Message.Chat.ID),
+		Text:      message,
 		ParseMode: models.ParseModeMarkdown,
 	})
 	if err != nil {
@@ -25,7 +26,7 @@ func meHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
 }
 
 func defaultStartHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
-	message := `Welcome to this friendly bot!`
+	message := i18n.T(Language(ctx), "Welcome to this friendly bot!")
 
 	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
 		ChatID: update.Message.Chat.ID,
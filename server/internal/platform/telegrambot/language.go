@@ -0,0 +1,81 @@
+package telegrambot
+
+import (
+	"context"
+
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+)
+
+type languageKey struct{}
+
+// LanguageResolver picks the language of each user.
+type LanguageResolver interface {
+	// Language returns the user language given the Telegram language_code of their message, which is empty when
+	// the user is not messaging the bot (e.g. to build their command menu).
+	Language(userID int64, languageCode string) string
+	// DefaultLanguage is used for the users whose language is unknown.
+	DefaultLanguage() string
+}
+
+// SetLanguageResolver sets how the user language is picked. Without a resolver the Telegram language_code is
+// used if supported, English otherwise.
+func (t *TelegramBot) SetLanguageResolver(languages LanguageResolver) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.languages = languages
+}
+
+// Language returns the language of the user that sent the command being handled.
+func Language(ctx context.Context) string {
+	language, _ := ctx.Value(languageKey{}).(string)
+	return language
+}
+
+func (t *TelegramBot) language(update *models.Update) string {
+	var languageCode string
+	if user := sender(update); user != nil {
+		languageCode = user.LanguageCode
+	}
+
+	t.mu.RLock()
+	languages := t.languages
+	t.mu.RUnlock()
+
+	if languages != nil {
+		return languages.Language(senderID(update), languageCode)
+	}
+
+	if language := i18n.Match(languageCode); language != "" {
+		return language
+	}
+
+	return i18n.English
+}
+
+// userLanguage returns the language of a user that is not messaging the bot.
+func (t *TelegramBot) userLanguage(userID int64) string {
+	t.mu.RLock()
+	languages := t.languages
+	t.mu.RUnlock()
+
+	if languages != nil {
+		return languages.Language(userID, "")
+	}
+
+	return i18n.English
+}
+
+func (t *TelegramBot) defaultLanguage() string {
+	t.mu.RLock()
+	languages := t.languages
+	t.mu.RUnlock()
+
+	if languages != nil {
+		return languages.DefaultLanguage()
+	}
+
+	return i18n.English
+}
@@ -0,0 +1,219 @@
+package telegrambot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+)
+
+// Permissions tells the bot who can run the restricted commands, so /help and the command menus only show each
+// user the commands they can run.
+type Permissions interface {
+	// Allowed reports whether the user can run the command.
+	Allowed(userID int64, command string) (bool, error)
+	// Restricted reports whether the command is not allowed to everyone.
+	Restricted(command string) bool
+	// PrivilegedUsers returns the users allowed to run some restricted command.
+	PrivilegedUsers() ([]int64, error)
+}
+
+// MenuStore persists the chats with a command menu of their own, so the menus of the users that lost their
+// permissions while the bot was stopped are deleted as well.
+type MenuStore interface {
+	CommandMenuChats() ([]int64, error)
+	SetCommandMenuChats(chatIDs []int64) error
+}
+
+// SetMenuStore keeps the chats with a command menu of their own in the store. Without it they are only kept in
+// memory.
+func (t *TelegramBot) SetMenuStore(store MenuStore) {
+	t.menuMu.Lock()
+	defer t.menuMu.Unlock()
+
+	t.menuStore = store
+}
+
+// SetPermissions filters /help and the command menus with the given permissions. Without them every command
+// with a description is shown to everyone.
+func (t *TelegramBot) SetPermissions(permissions Permissions) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.permissions = permissions
+}
+
+// RefreshCommandMenus publishes the menu with the public commands to everyone and a menu with the public and
+// the allowed restricted commands to the chat of each privileged user. It must be called again whenever the
+// users permissions change.
+func (t *TelegramBot) RefreshCommandMenus(ctx context.Context) error {
+	t.menuMu.Lock()
+	defer t.menuMu.Unlock()
+
+	t.mu.RLock()
+	permissions := t.permissions
+	t.mu.RUnlock()
+
+	public, err := t.visibleCommands(func(cmd Command) (bool, error) {
+		return permissions == nil || !permissions.Restricted(cmd.Pattern), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// The menu without language code is shown to the users whose language is not supported.
+	_, err = t.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+		Commands: botCommands(public, t.defaultLanguage()),
+		Scope:    &models.BotCommandScopeDefault{},
+	})
+	if err != nil {
+		return fmt.Errorf("error setting telegram bot commands: %w", err)
+	}
+
+	for _, language := range i18n.Languages() {
+		_, err = t.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands:     botCommands(public, language),
+			Scope:        &models.BotCommandScopeDefault{},
+			LanguageCode: language,
+		})
+		if err != nil {
+			return fmt.Errorf("error setting telegram bot '%s' commands: %w", language, err)
+		}
+	}
+
+	if permissions == nil {
+		return nil
+	}
+
+	users, err := permissions.PrivilegedUsers()
+	if err != nil {
+		return fmt.Errorf("error getting privileged users: %w", err)
+	}
+
+	for _, userID := range users {
+		commands, err := t.visibleCommands(func(cmd Command) (bool, error) {
+			return permissions.Allowed(userID, cmd.Pattern)
+		})
+		if err != nil {
+			return err
+		}
+
+		// The chat may not exist yet if the user never talked to the bot, keep going with the rest of them.
+		_, err = t.bot.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands: botCommands(commands, t.userLanguage(userID)),
+			Scope:    &models.BotCommandScopeChat{ChatID: userID},
+		})
+		if err != nil {
+			slog.Warn("error setting telegram bot chat commands",
+				slog.Int64("chat_id", userID),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	scopedChats := t.scopedChats
+	if t.menuStore != nil {
+		scopedChats, err = t.menuStore.CommandMenuChats()
+		if err != nil {
+			return fmt.Errorf("error getting command menu chats: %w", err)
+		}
+	}
+
+	for _, chatID := range scopedChats {
+		if slices.Contains(users, chatID) {
+			continue
+		}
+
+		_, err = t.bot.DeleteMyCommands(ctx, &bot.DeleteMyCommandsParams{
+			Scope: &models.BotCommandScopeChat{ChatID: chatID},
+		})
+		if err != nil {
+			slog.Warn("error deleting telegram bot chat commands",
+				slog.Int64("chat_id", chatID),
+				slog.Any("error", err),
+			)
+		}
+	}
+
+	t.scopedChats = users
+	if t.menuStore != nil {
+		if err := t.menuStore.SetCommandMenuChats(users); err != nil {
+			return fmt.Errorf("error setting command menu chats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// visibleCommands returns the commands with a description accepted by the filter. Commands without description
+// are never shown, so the dev can register hidden commands.
+func (t *TelegramBot) visibleCommands(filter func(Command) (bool, error)) ([]Command, error) {
+	var visible []Command
+	for _, cmd := range t.Commands() {
+		if len(cmd.Description) == 0 {
+			continue
+		}
+
+		ok, err := filter(cmd)
+		if err != nil {
+			return nil, fmt.Errorf("error filtering '%s' command: %w", cmd.Pattern, err)
+		}
+
+		if ok {
+			visible = append(visible, cmd)
+		}
+	}
+
+	return visible, nil
+}
+
+func (t *TelegramBot) helpHandler(ctx context.Context, b *bot.Bot, update *models.Update) {
+	t.mu.RLock()
+	permissions := t.permissions
+	t.mu.RUnlock()
+
+	userID := senderID(update)
+	commands, err := t.visibleCommands(func(cmd Command) (bool, error) {
+		if permissions == nil {
+			return true, nil
+		}
+
+		return permissions.Allowed(userID, cmd.Pattern)
+	})
+	if err != nil {
+		Logger(ctx).Error("error listing commands",
+			slog.Int64("user_id", userID),
+			slog.Any("error", err),
+		)
+		reply(ctx, b, update, i18n.T(Language(ctx), "Sorry, the commands could not be listed, please try again later."))
+		return
+	}
+
+	var message strings.Builder
+	message.WriteString(i18n.T(Language(ctx), "Available commands:"))
+	message.WriteString("\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&message, "\n%s - %s", cmd.Usage(), i18n.T(Language(ctx), cmd.Description))
+	}
+
+	reply(ctx, b, update, message.String())
+}
+
+// botCommands returns the menu entries of the commands, with their description translated to the language.
+func botCommands(commands []Command, language string) []models.BotCommand {
+	botCommands := make([]models.BotCommand, 0, len(commands))
+	for _, cmd := range commands {
+		botCommands = append(botCommands, models.BotCommand{
+			Command:     cmd.Pattern[1:],
+			Description: i18n.T(language, cmd.Description),
+		})
+	}
+
+	return botCommands
+}
@@ -0,0 +1,300 @@
+package telegrambot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"expvar"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"golang.org/x/time/rate"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+)
+
+// Middleware wraps a command or callback handler, the command and request ID are available through the context.
+type Middleware = bot.Middleware
+
+type (
+	commandKey   struct{}
+	requestIDKey struct{}
+)
+
+var (
+	commandCalls    = expvar.NewMap("telegrambot_command_calls")
+	commandRejected = expvar.NewMap("telegrambot_command_rejected")
+	commandPanics   = expvar.NewMap("telegrambot_command_panics")
+	commandDuration = expvar.NewMap("telegrambot_command_duration_ms")
+)
+
+// Authorizer decides whether the user is allowed to run the command.
+type Authorizer interface {
+	Authorize(ctx context.Context, userID int64, command string) (bool, error)
+}
+
+// CommandName returns the command being handled.
+func CommandName(ctx context.Context) string {
+	command, _ := ctx.Value(commandKey{}).(string)
+	return command
+}
+
+// RequestID returns the ID assigned by the Logging middleware to the update being handled.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Logger returns the default logger with the command and request ID attributes.
+func Logger(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if command := CommandName(ctx); command != "" {
+		logger = logger.With(slog.String("command", command))
+	}
+
+	if requestID := RequestID(ctx); requestID != "" {
+		logger = logger.With(slog.String("request_id", requestID))
+	}
+
+	return logger
+}
+
+// Recover logs the panics raised by the handlers instead of crashing the bot.
+func Recover() Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			defer func() {
+				if r := recover(); r != nil {
+					commandPanics.Add(CommandName(ctx), 1)
+					Logger(ctx).Error("panic handling command",
+						slog.Any("panic", r),
+						slog.String("stack", string(debug.Stack())),
+					)
+				}
+			}()
+
+			next(ctx, b, update)
+		}
+	}
+}
+
+// Logging assigns a request ID to each update and logs the handled commands.
+func Logging() Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			ctx = context.WithValue(ctx, requestIDKey{}, newRequestID())
+			start := time.Now()
+
+			next(ctx, b, update)
+
+			Logger(ctx).Info("command handled",
+				slog.Int64("user_id", senderID(update)),
+				slog.Int64("chat_id", chatID(update)),
+				slog.Duration("duration", time.Since(start)),
+			)
+		}
+	}
+}
+
+// Metrics counts the handled commands and their duration, published through expvar.
+func Metrics() Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			start := time.Now()
+			commandCalls.Add(CommandName(ctx), 1)
+
+			next(ctx, b, update)
+
+			commandDuration.Add(CommandName(ctx), time.Since(start).Milliseconds())
+		}
+	}
+}
+
+// Authorize runs the handler only if the authorizer allows the message sender to run the command, politely
+// refusing it otherwise.
+func Authorize(authorizer Authorizer) Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			userID := senderID(update)
+
+			authorized, err := authorizer.Authorize(ctx, userID, CommandName(ctx))
+			if err != nil {
+				Logger(ctx).Error("error authorizing command",
+					slog.Int64("user_id", userID),
+					slog.Any("error", err),
+				)
+			}
+
+			if authorized {
+				next(ctx, b, update)
+				return
+			}
+
+			commandRejected.Add(CommandName(ctx), 1)
+			commandDenied(ctx, errNotAuthorized)
+			reply(ctx, b, update, i18n.T(Language(ctx), "Sorry, you are not authorized to use this command."))
+		}
+	}
+}
+
+// limiterSweepInterval is how often the idle user limiters are evicted.
+const limiterSweepInterval = time.Minute
+
+// UserRateLimiter limits the commands each user can run per minute.
+type UserRateLimiter struct {
+	mu       sync.Mutex
+	limit    rate.Limit
+	burst    int
+	limiters map[int64]*rate.Limiter
+	swept    time.Time
+}
+
+func NewUserRateLimiter(commandsPerMinute, burst int) *UserRateLimiter {
+	return &UserRateLimiter{
+		limit:    rate.Limit(float64(commandsPerMinute) / 60),
+		burst:    burst,
+		limiters: make(map[int64]*rate.Limiter),
+	}
+}
+
+// SetLimit updates the limit of every user.
+func (l *UserRateLimiter) SetLimit(commandsPerMinute, burst int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit = rate.Limit(float64(commandsPerMinute) / 60)
+	l.burst = burst
+	for _, limiter := range l.limiters {
+		limiter.SetLimit(l.limit)
+		limiter.SetBurst(l.burst)
+	}
+}
+
+func (l *UserRateLimiter) allow(userID int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if time.Since(l.swept) > limiterSweepInterval {
+		l.sweep()
+	}
+
+	limiter, ok := l.limiters[userID]
+	if !ok {
+		limiter = rate.NewLimiter(l.limit, l.burst)
+		l.limiters[userID] = limiter
+	}
+
+	return limiter.Allow()
+}
+
+// sweep evicts the limiters of the users idle long enough to get the whole burst back, which are the same as new
+// ones. The caller must hold the lock.
+func (l *UserRateLimiter) sweep() {
+	for userID, limiter := range l.limiters {
+		if limiter.Tokens() >= float64(l.burst) {
+			delete(l.limiters, userID)
+		}
+	}
+
+	l.swept = time.Now()
+}
+
+// Middleware refuses the commands of the users that exceeded their limit.
+func (l *UserRateLimiter) Middleware() Middleware {
+	return func(next bot.HandlerFunc) bot.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update) {
+			if l.allow(senderID(update)) {
+				next(ctx, b, update)
+				return
+			}
+
+			commandRejected.Add(CommandName(ctx), 1)
+			commandDenied(ctx, errRateLimited)
+			Logger(ctx).Warn("command rate limited", slog.Int64("user_id", senderID(update)))
+			reply(ctx, b, update, i18n.T(Language(ctx), "Too many commands, please wait a moment and try again."))
+		}
+	}
+}
+
+func chain(handler bot.HandlerFunc, middlewares ...Middleware) bot.HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// sender returns the user that sent the message or pressed the button, nil if unknown.
+func sender(update *models.Update) *models.User {
+	switch {
+	case update.CallbackQuery != nil:
+		return &update.CallbackQuery.From
+	case update.Message != nil:
+		return update.Message.From
+	}
+
+	return nil
+}
+
+// senderID returns the ID of the user that sent the message, which is the chat ID in private chats.
+func senderID(update *models.Update) int64 {
+	if user := sender(update); user != nil {
+		return user.ID
+	}
+
+	return update.Message.Chat.ID
+}
+
+// chatID returns the chat of the message or of the pressed button, which is the sender one if unknown.
+func chatID(update *models.Update) int64 {
+	if query := update.CallbackQuery; query != nil {
+		if message := query.Message.Message; message != nil {
+			return message.Chat.ID
+		}
+
+		return query.From.ID
+	}
+
+	return update.Message.Chat.ID
+}
+
+// reply sends the text to the chat of the message or, for the pressed buttons, shows it as the callback answer.
+func reply(ctx context.Context, b *bot.Bot, update *models.Update, text string) {
+	if query := update.CallbackQuery; query != nil {
+		_, err := b.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: query.ID,
+			Text:            text,
+			ShowAlert:       true,
+		})
+		if err != nil {
+			Logger(ctx).Error("error answering callback query",
+				slog.Int64("user_id", query.From.ID),
+				slog.Any("error", err),
+			)
+		}
+
+		return
+	}
+
+	_, err := b.SendMessage(ctx, &bot.SendMessageParams{
+		ChatID: update.Message.Chat.ID,
+		Text:   text,
+	})
+	if err != nil {
+		Logger(ctx).Error("error sending message",
+			slog.Int64("chat_id", update.Message.Chat.ID),
+			slog.Any("error", err),
+		)
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	return hex.EncodeToString(id)
+}
@@ -0,0 +1,107 @@
+package telegrambot
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+type auditorFunc func(ctx context.Context, record CommandRecord)
+
+func (f auditorFunc) RecordCommand(ctx context.Context, record CommandRecord) {
+	f(ctx, record)
+}
+
+func TestChatID(t *testing.T) {
+	tests := []struct {
+		name   string
+		update *models.Update
+		want   int64
+	}{
+		{
+			name:   "message",
+			update: &models.Update{Message: &models.Message{Chat: models.Chat{ID: 10}, From: &models.User{ID: 1}}},
+			want:   10,
+		},
+		{
+			name: "button",
+			update: &models.Update{CallbackQuery: &models.CallbackQuery{
+				From:    models.User{ID: 1},
+				Message: models.MaybeInaccessibleMessage{Message: &models.Message{Chat: models.Chat{ID: 10}}},
+			}},
+			want: 10,
+		},
+		{
+			name:   "button of an inaccessible message",
+			update: &models.Update{CallbackQuery: &models.CallbackQuery{From: models.User{ID: 1}}},
+			want:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chatID(tt.update); got != tt.want {
+				t.Errorf("chatID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	tests := []struct {
+		name    string
+		command string
+		update  *models.Update
+		handler bot.HandlerFunc
+		want    CommandRecord
+	}{
+		{
+			name:    "command",
+			command: "/subscribe",
+			update: &models.Update{Message: &models.Message{
+				Text: "/subscribe@MyBot madrid",
+				Chat: models.Chat{ID: 10},
+				From: &models.User{ID: 1},
+			}},
+			handler: func(ctx context.Context, b *bot.Bot, update *models.Update) {},
+			want:    CommandRecord{UserID: 1, ChatID: 10, Command: "/subscribe", Args: "madrid"},
+		},
+		{
+			name:    "failed button",
+			command: "outcome:",
+			update: &models.Update{CallbackQuery: &models.CallbackQuery{
+				From:    models.User{ID: 1},
+				Data:    "outcome:unsubscribe",
+				Message: models.MaybeInaccessibleMessage{Message: &models.Message{Chat: models.Chat{ID: 10}}},
+			}},
+			handler: func(ctx context.Context, b *bot.Bot, update *models.Update) { CommandFailed(ctx, errFailed) },
+			want:    CommandRecord{UserID: 1, ChatID: 10, Command: "outcome:unsubscribe", Err: errFailed},
+		},
+		{
+			name:    "panic",
+			command: "/status",
+			update:  &models.Update{Message: &models.Message{Text: "/status", From: &models.User{ID: 1}}},
+			handler: func(ctx context.Context, b *bot.Bot, update *models.Update) { panic("boom") },
+			want:    CommandRecord{UserID: 1, Command: "/status", Err: errPanic},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []CommandRecord
+			auditor := auditorFunc(func(ctx context.Context, record CommandRecord) { got = append(got, record) })
+
+			ctx := context.WithValue(context.Background(), commandKey{}, tt.command)
+			chain(tt.handler, Recover(), Audit(auditor))(ctx, nil, tt.update)
+
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("records = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
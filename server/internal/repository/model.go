@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when the requested entity doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrNewerSchema is returned opening a database migrated by a newer server version.
+	ErrNewerSchema = errors.New("database schema is newer than the supported one")
+
+	// ErrInvalidQuietHours and ErrInvalidTimeZone are the Preferences validation errors, meant to be translated.
+	ErrInvalidQuietHours = errors.New("invalid quiet hours, use HH:MM-HH:MM or off")
+	ErrInvalidTimeZone   = errors.New("unknown time zone, use a name like America/Montevideo")
+)
+
+// ScreenshotHash is the perceptual hash of a scrapper screenshot taken for a given target.
+type ScreenshotHash struct {
+	Hash      uint64    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ResultStatus is what the scrapper found in a check.
+type ResultStatus string
+
+const (
+	ResultAvailable   ResultStatus = "available"
+	ResultUnavailable ResultStatus = "unavailable"
+	// ResultFailed is a check that couldn't tell whether there were available hours, e.g. the page didn't load.
+	ResultFailed ResultStatus = "failed"
+)
+
+// ResultStatuses lists the valid result statuses.
+var ResultStatuses = []ResultStatus{ResultAvailable, ResultUnavailable, ResultFailed}
+
+// Result is a scrapper result as it was received by the server. Debug is set by the scrapper for the results that
+// are only sent to the owners, which are both the unavailable and the failed checks.
+type Result struct {
+	ID     uint64       `json:"id"`
+	Target string       `json:"target"`
+	Debug  bool         `json:"debug"`
+	Status ResultStatus `json:"status,omitempty"`
+	// Message is the text sent by the scrapper, e.g. the error of the failed checks.
+	Message       string    `json:"message"`
+	ScreenshotID  string    `json:"screenshot_id,omitempty"`
+	LayoutChanged bool      `json:"layout_changed"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// CheckStatus returns the result status. The results stored without one, before the scrapper reported it, are
+// derived from Debug, so their failed checks count as unavailable.
+func (r Result) CheckStatus() ResultStatus {
+	switch {
+	case r.Status != "":
+		return r.Status
+	case r.Debug:
+		return ResultUnavailable
+	}
+
+	return ResultAvailable
+}
+
+// OutcomeStatus is what happened to the subscriber after an availability notification.
+type OutcomeStatus string
+
+const (
+	OutcomeBooked        OutcomeStatus = "booked"
+	OutcomeMissed        OutcomeStatus = "missed"
+	OutcomeNotInterested OutcomeStatus = "not_interested"
+)
+
+// Outcomes lists the valid outcome statuses.
+var Outcomes = []OutcomeStatus{OutcomeBooked, OutcomeMissed, OutcomeNotInterested}
+
+// Outcome is the status reported by a subscriber for an availability event, the result that notified it.
+type Outcome struct {
+	ResultID   uint64        `json:"result_id"`
+	Subscriber int64         `json:"subscriber"`
+	Status     OutcomeStatus `json:"status"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// Screenshot describes an archived screenshot; its ID is the SHA-256 of the original image.
+type Screenshot struct {
+	ID       string    `json:"id"`
+	Size     int64     `json:"size"`
+	StoredAt time.Time `json:"stored_at"`
+}
+
+// Role grants access to the bot admin commands, each role includes the permissions of the lower ones.
+type Role string
+
+const (
+	RoleViewer   Role = "viewer"
+	RoleOperator Role = "operator"
+	RoleAdmin    Role = "admin"
+	RoleOwner    Role = "owner"
+)
+
+// Roles lists the valid roles, from the lowest to the highest.
+var Roles = []Role{RoleViewer, RoleOperator, RoleAdmin, RoleOwner}
+
+// Level returns the role position in Roles, starting at 1; 0 means no (or an unknown) role.
+func (r Role) Level() int {
+	for i, role := range Roles {
+		if role == r {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// Includes reports whether the role grants the permissions of the other one.
+func (r Role) Includes(other Role) bool {
+	return r.Level() > 0 && r.Level() >= other.Level()
+}
+
+// Profile holds the per user settings.
+type Profile struct {
+	// Language is the language chosen with /language, empty to use TelegramLanguage.
+	Language string `json:"language,omitempty"`
+	// TelegramLanguage is the last supported language_code seen in the user messages.
+	TelegramLanguage string `json:"telegram_language,omitempty"`
+	// Target is the only target the subscriber is notified about, chosen with /subscribe, empty for all of them.
+	Target string `json:"target,omitempty"`
+
+	Preferences Preferences `json:"preferences"`
+	// LastNotifiedAt is when the subscriber got the last availability notification.
+	LastNotifiedAt time.Time `json:"last_notified_at,omitempty"`
+	// ConfirmedAt is when the user subscribed or last confirmed their subscription, ConfirmationAskedAt is when
+	// they were asked to confirm it, zero if they have no pending question.
+	ConfirmedAt         time.Time `json:"confirmed_at,omitempty"`
+	ConfirmationAskedAt time.Time `json:"confirmation_asked_at,omitempty"`
+}
+
+// Preferences are the subscriber notification settings, the zero value sends every notification with sound and
+// screenshot.
+type Preferences struct {
+	// TimeZone is the IANA time zone of the quiet hours, empty for the configured default one.
+	TimeZone string `json:"time_zone,omitempty"`
+	// QuietStart and QuietEnd are the "HH:MM" quiet hours, when the notifications are sent without sound. Empty
+	// for no quiet hours.
+	QuietStart string `json:"quiet_start,omitempty"`
+	QuietEnd   string `json:"quiet_end,omitempty"`
+	// Silent sends every notification without sound.
+	Silent bool `json:"silent,omitempty"`
+	// HideScreenshots sends the notifications without the page screenshot.
+	HideScreenshots bool `json:"hide_screenshots,omitempty"`
+	// ReminderInterval is the minimum time between availability notifications, zero to get all of them.
+	ReminderInterval time.Duration `json:"reminder_interval,omitempty"`
+}
+
+// Validate checks the time zone is a known one and the quiet hours are either both set or both empty.
+func (p Preferences) Validate() error {
+	if p.TimeZone != "" {
+		if _, err := time.LoadLocation(p.TimeZone); err != nil || strings.EqualFold(p.TimeZone, "local") {
+			return ErrInvalidTimeZone
+		}
+	}
+
+	if p.QuietStart != "" || p.QuietEnd != "" {
+		if _, err := ParseClock(p.QuietStart); err != nil {
+			return ErrInvalidQuietHours
+		}
+
+		if _, err := ParseClock(p.QuietEnd); err != nil {
+			return ErrInvalidQuietHours
+		}
+	}
+
+	if p.ReminderInterval < 0 {
+		return errors.New("the reminder interval can't be negative")
+	}
+
+	return nil
+}
+
+// ParseClock returns the minutes since midnight of an "HH:MM" time.
+func ParseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// AuditOutcome is how an audited action ended.
+type AuditOutcome string
+
+const (
+	AuditSucceeded AuditOutcome = "succeeded"
+	// AuditDenied is the outcome of the actions the actor was not allowed to run.
+	AuditDenied AuditOutcome = "denied"
+	AuditFailed AuditOutcome = "failed"
+)
+
+// Audit actors other than the Telegram users, whose actor is TelegramActor(userID).
+const (
+	// AuditActorAPI is the owner calling the HTTP API with the owner token.
+	AuditActorAPI = "api"
+	// AuditActorScrapper is the scrapper calling the HTTP API with the scrapper token.
+	AuditActorScrapper = "scrapper"
+	// AuditActorSystem is the server itself, e.g. expiring the subscriptions.
+	AuditActorSystem = "system"
+	// AuditActorOfflineCLI is the admin subcommands run on the database of a stopped server.
+	AuditActorOfflineCLI = "offline-cli"
+)
+
+// TelegramActor returns the audit actor of the Telegram user.
+func TelegramActor(userID int64) string {
+	return fmt.Sprintf("telegram:%d", userID)
+}
+
+// AuditEntry records who ran an action, on what and how it ended. The entries are never modified, only deleted
+// once older than the audit retention.
+type AuditEntry struct {
+	ID     uint64 `json:"id"`
+	Actor  string `json:"actor"`
+	Action string `json:"action"`
+	// Target is what the action was run on, e.g. the chat ID or the command arguments.
+	Target  string       `json:"target,omitempty"`
+	Outcome AuditOutcome `json:"outcome"`
+	// Error describes why the action was denied or failed.
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditFilter selects the audit entries, its zero fields match every entry.
+type AuditFilter struct {
+	Actor  string
+	Action string
+	Since  time.Time
+	// Limit is the maximum amount of entries returned, 0 for no limit.
+	Limit int
+}
+
+// Matches reports whether the entry is selected by the filter, ignoring the Limit.
+func (f AuditFilter) Matches(entry AuditEntry) bool {
+	return (f.Actor == "" || entry.Actor == f.Actor) &&
+		(f.Action == "" || entry.Action == f.Action) &&
+		!entry.CreatedAt.Before(f.Since)
+}
@@ -1,5 +1,7 @@
 package repository
 
+import "time"
+
 // TODO: move to another package.
 
 type Repository interface {
@@ -10,4 +12,65 @@ type Repository interface {
 
 	ManageDebug(enable bool) error
 	DebugEnabled() (bool, error)
+
+	// AddScreenshotHash appends the hash to the target history, the oldest entries are discarded, and returns the
+	// history it had before, oldest first. Both are done atomically, so concurrent calls see each other's hashes.
+	AddScreenshotHash(target string, hash ScreenshotHash) ([]ScreenshotHash, error)
+	// ScreenshotHashes returns the target history, oldest first.
+	ScreenshotHashes(target string) ([]ScreenshotHash, error)
+
+	// AddResult stores the result, as the latest one of its target as well, and returns its assigned ID.
+	AddResult(result Result) (uint64, error)
+	// Result returns ErrNotFound if there is no result with the given ID.
+	Result(id uint64) (Result, error)
+	// Results returns up to limit results, newest first.
+	Results(limit int) ([]Result, error)
+	// ResultsSince returns the results created since the given time, newest first.
+	ResultsSince(since time.Time) ([]Result, error)
+	// LastResult returns the latest result of the target, ErrNotFound if it doesn't have any.
+	LastResult(target string) (Result, error)
+	// LastAvailableResult returns the latest result of the target, of any target if empty, whose check found
+	// availability, ErrNotFound if there is none.
+	LastAvailableResult(target string) (Result, error)
+	// DeleteResultsBefore deletes the results created before the given time, except the latest one of each
+	// target, and returns how many were deleted.
+	DeleteResultsBefore(before time.Time) (int, error)
+
+	// PutScreenshot stores the screenshot data, storing an existing one refreshes its StoredAt.
+	PutScreenshot(id string, data []byte) error
+	// Screenshot returns ErrNotFound if there is no screenshot with the given ID.
+	Screenshot(id string) ([]byte, error)
+	Screenshots() ([]Screenshot, error)
+	DeleteScreenshot(id string) error
+
+	SetRole(userID int64, role Role) error
+	RemoveRole(userID int64) error
+	// UserRoles returns the role of each user that has one.
+	UserRoles() (map[int64]Role, error)
+
+	// CommandMenuChats returns the chats the bot published a command menu of their own to.
+	CommandMenuChats() ([]int64, error)
+	SetCommandMenuChats(chatIDs []int64) error
+
+	// SetOutcome stores the outcome, replacing the one reported by the subscriber for the same result.
+	SetOutcome(outcome Outcome) error
+	// Outcomes returns every outcome, sorted by result ID.
+	Outcomes() ([]Outcome, error)
+
+	// Profile returns the zero Profile if the user doesn't have one.
+	Profile(userID int64) (Profile, error)
+	SetProfile(userID int64, profile Profile) error
+	// UpdateProfile applies the update to the user profile, the zero Profile if they don't have one, and returns
+	// the updated profile. The read and the write are atomic, so concurrent updates of different fields don't
+	// overwrite each other; update may be called more than once and nothing is written if it returns an error,
+	// which is returned wrapped, or doesn't change the profile.
+	UpdateProfile(userID int64, update func(profile *Profile) error) (Profile, error)
+
+	// AddAuditEntry appends the entry to the audit log and returns its assigned ID.
+	AddAuditEntry(entry AuditEntry) (uint64, error)
+	// AuditEntries returns the entries selected by the filter, newest first.
+	AuditEntries(filter AuditFilter) ([]AuditEntry, error)
+	// DeleteAuditEntriesBefore deletes the entries created before the given time and returns how many were
+	// deleted. Their IDs are not assigned again.
+	DeleteAuditEntriesBefore(before time.Time) (int, error)
 }
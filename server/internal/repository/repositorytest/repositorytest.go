@@ -0,0 +1,1018 @@
+// Package repositorytest is the conformance test suite of the repository.Repository implementations, so every
+// store keeps the same semantics: the results of each operation, their idempotency, the error paths and the
+// concurrent use.
+package repositorytest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// Run runs the suite, newRepository must return an empty repository, which the suite closes.
+func Run(t *testing.T, newRepository func(t *testing.T) repository.Repository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, db repository.Repository)
+	}{
+		{"Subscribers", testSubscribers},
+		{"Debug", testDebug},
+		{"ScreenshotHashes", testScreenshotHashes},
+		{"Results", testResults},
+		{"DeleteResults", testDeleteResults},
+		{"Screenshots", testScreenshots},
+		{"Roles", testRoles},
+		{"CommandMenuChats", testCommandMenuChats},
+		{"Outcomes", testOutcomes},
+		{"Profiles", testProfiles},
+		{"UpdateProfile", testUpdateProfile},
+		{"Audit", testAudit},
+		{"DeleteAuditEntries", testDeleteAuditEntries},
+		{"Idempotency", testIdempotency},
+		{"NotFound", testNotFound},
+		{"Copies", testCopies},
+		{"ConcurrentSubscribers", testConcurrentSubscribers},
+		{"ConcurrentResults", testConcurrentResults},
+		{"ConcurrentScreenshotHashes", testConcurrentScreenshotHashes},
+		{"ConcurrentWrites", testConcurrentWrites},
+		{"ConcurrentProfileUpdates", testConcurrentProfileUpdates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newRepository(t)
+			defer func() {
+				if err := db.Close(); err != nil {
+					t.Errorf("error closing repository: %v", err)
+				}
+			}()
+
+			tt.test(t, db)
+		})
+	}
+}
+
+func check(t *testing.T, err error) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func checkNotFound(t *testing.T, err error) {
+	t.Helper()
+
+	if !errors.Is(err, repository.ErrNotFound) {
+		t.Fatalf("error = %v, want %v", err, repository.ErrNotFound)
+	}
+}
+
+func testSubscribers(t *testing.T, db repository.Repository) {
+	subs, err := db.Subscribers()
+	check(t, err)
+
+	if len(subs) != 0 {
+		t.Fatalf("subscribers = %v, want none", subs)
+	}
+
+	check(t, db.AddSubscriber(2))
+	check(t, db.AddSubscriber(1))
+	check(t, db.AddSubscriber(-100)) // Group chats have negative IDs.
+	check(t, db.AddSubscriber(2))
+
+	subs, err = db.Subscribers()
+	check(t, err)
+
+	slices.Sort(subs)
+	if want := []int64{-100, 1, 2}; !slices.Equal(subs, want) {
+		t.Fatalf("subscribers = %v, want %v", subs, want)
+	}
+
+	check(t, db.RemoveSubscriber(1))
+	check(t, db.RemoveSubscriber(3))
+
+	subs, err = db.Subscribers()
+	check(t, err)
+
+	slices.Sort(subs)
+	if want := []int64{-100, 2}; !slices.Equal(subs, want) {
+		t.Fatalf("subscribers = %v, want %v", subs, want)
+	}
+}
+
+func testDebug(t *testing.T, db repository.Repository) {
+	enabled, err := db.DebugEnabled()
+	check(t, err)
+
+	if enabled {
+		t.Fatal("debug enabled by default")
+	}
+
+	for _, want := range []bool{true, false} {
+		check(t, db.ManageDebug(want))
+
+		enabled, err := db.DebugEnabled()
+		check(t, err)
+
+		if enabled != want {
+			t.Fatalf("debug = %t, want %t", enabled, want)
+		}
+	}
+}
+
+func testScreenshotHashes(t *testing.T, db repository.Repository) {
+	hashes, err := db.ScreenshotHashes("default")
+	check(t, err)
+
+	if len(hashes) != 0 {
+		t.Fatalf("hashes = %v, want none", hashes)
+	}
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 105 {
+		// The hashes are 64 bits, the highest one included.
+		hash := repository.ScreenshotHash{Hash: ^uint64(0) - uint64(i), CreatedAt: base.Add(time.Duration(i) * time.Minute)}
+		previous, err := db.AddScreenshotHash("default", hash)
+		check(t, err)
+
+		// The previous history doesn't include the added hash.
+		if len(previous) != min(i, 100) {
+			t.Fatalf("got %d previous hashes, want %d", len(previous), min(i, 100))
+		}
+
+		if i > 0 && previous[len(previous)-1].Hash != hash.Hash+1 {
+			t.Fatalf("latest previous hash = %+v, want the one added before", previous[len(previous)-1])
+		}
+	}
+
+	previous, err := db.AddScreenshotHash("other", repository.ScreenshotHash{Hash: 1, CreatedAt: base})
+	check(t, err)
+
+	if len(previous) != 0 {
+		t.Fatalf("other target previous hashes = %v, want none", previous)
+	}
+
+	hashes, err = db.ScreenshotHashes("default")
+	check(t, err)
+
+	// The oldest ones are discarded.
+	if len(hashes) != 100 {
+		t.Fatalf("got %d hashes, want 100", len(hashes))
+	}
+
+	first, last := hashes[0], hashes[len(hashes)-1]
+	if first.Hash != ^uint64(0)-5 || !first.CreatedAt.Equal(base.Add(5*time.Minute)) {
+		t.Errorf("oldest hash = %+v, want the 6th one", first)
+	}
+
+	if last.Hash != ^uint64(0)-104 || !last.CreatedAt.Equal(base.Add(104*time.Minute)) {
+		t.Errorf("newest hash = %+v, want the 105th one", last)
+	}
+
+	hashes, err = db.ScreenshotHashes("other")
+	check(t, err)
+
+	if len(hashes) != 1 || hashes[0].Hash != 1 {
+		t.Fatalf("other target hashes = %v, want the added one", hashes)
+	}
+}
+
+func testResults(t *testing.T, db repository.Repository) {
+	_, err := db.Result(1)
+	checkNotFound(t, err)
+
+	_, err = db.LastResult("default")
+	checkNotFound(t, err)
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	added := []repository.Result{
+		{Target: "default", Debug: true, Status: repository.ResultFailed, Message: "Error", CreatedAt: base},
+		{Target: "default", Status: repository.ResultAvailable, Message: "There are hours available", ScreenshotID: "abc", CreatedAt: base.Add(time.Minute)},
+		// Without status, as the results stored before it was recorded.
+		{Target: "other", Debug: true, Message: "There are no available hours", LayoutChanged: true, CreatedAt: base.Add(2 * time.Minute)},
+	}
+
+	for i, result := range added {
+		id, err := db.AddResult(result)
+		check(t, err)
+
+		if id != uint64(i+1) {
+			t.Fatalf("result ID = %d, want %d", id, i+1)
+		}
+
+		added[i].ID = id
+	}
+
+	for _, want := range added {
+		result, err := db.Result(want.ID)
+		check(t, err)
+		checkResult(t, result, want)
+	}
+
+	results, err := db.Results(2)
+	check(t, err)
+	checkResults(t, results, added[2], added[1])
+
+	results, err = db.Results(10)
+	check(t, err)
+	checkResults(t, results, added[2], added[1], added[0])
+
+	results, err = db.ResultsSince(base.Add(time.Minute))
+	check(t, err)
+	checkResults(t, results, added[2], added[1])
+
+	results, err = db.ResultsSince(base.Add(time.Hour))
+	check(t, err)
+	checkResults(t, results)
+
+	last, err := db.LastResult("default")
+	check(t, err)
+	checkResult(t, last, added[1])
+
+	last, err = db.LastResult("other")
+	check(t, err)
+	checkResult(t, last, added[2])
+
+	last, err = db.LastAvailableResult("default")
+	check(t, err)
+	checkResult(t, last, added[1])
+
+	last, err = db.LastAvailableResult("")
+	check(t, err)
+	checkResult(t, last, added[1])
+
+	// The results stored without status are available unless they are debug ones.
+	legacy := repository.Result{Target: "other", CreatedAt: base.Add(3 * time.Minute)}
+	legacy.ID, err = db.AddResult(legacy)
+	check(t, err)
+
+	_, err = db.AddResult(repository.Result{Target: "other", Status: repository.ResultFailed, CreatedAt: base.Add(4 * time.Minute)})
+	check(t, err)
+
+	last, err = db.LastAvailableResult("other")
+	check(t, err)
+	checkResult(t, last, legacy)
+}
+
+func testDeleteResults(t *testing.T, db repository.Repository) {
+	deleted, err := db.DeleteResultsBefore(time.Now())
+	check(t, err)
+
+	if deleted != 0 {
+		t.Fatalf("deleted %d results from an empty repository", deleted)
+	}
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	added := []repository.Result{
+		{Target: "default", CreatedAt: base},
+		{Target: "other", CreatedAt: base.Add(time.Minute)},
+		{Target: "default", CreatedAt: base.Add(2 * time.Minute)},
+		{Target: "default", CreatedAt: base.Add(3 * time.Minute)},
+	}
+
+	for i, result := range added {
+		added[i].ID, err = db.AddResult(result)
+		check(t, err)
+	}
+
+	// The latest result of each target is kept, even if it's old.
+	deleted, err = db.DeleteResultsBefore(base.Add(3 * time.Minute))
+	check(t, err)
+
+	if deleted != 2 {
+		t.Errorf("deleted %d results, want 2", deleted)
+	}
+
+	for _, i := range []int{0, 2} {
+		_, err := db.Result(added[i].ID)
+		checkNotFound(t, err)
+	}
+
+	results, err := db.Results(10)
+	check(t, err)
+	checkResults(t, results, added[3], added[1])
+
+	last, err := db.LastResult("other")
+	check(t, err)
+	checkResult(t, last, added[1])
+
+	// The IDs are not reused.
+	id, err := db.AddResult(repository.Result{Target: "other", CreatedAt: base.Add(4 * time.Minute)})
+	check(t, err)
+
+	if id != 5 {
+		t.Errorf("result ID = %d, want 5", id)
+	}
+
+	deleted, err = db.DeleteResultsBefore(base.Add(time.Hour))
+	check(t, err)
+
+	if deleted != 1 {
+		t.Errorf("deleted %d results, want the older one of the other target", deleted)
+	}
+}
+
+func checkResults(t *testing.T, results []repository.Result, want ...repository.Result) {
+	t.Helper()
+
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+
+	for i := range results {
+		checkResult(t, results[i], want[i])
+	}
+}
+
+func checkResult(t *testing.T, result, want repository.Result) {
+	t.Helper()
+
+	if !result.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("result %d created at %s, want %s", result.ID, result.CreatedAt, want.CreatedAt)
+	}
+
+	result.CreatedAt = want.CreatedAt
+	if result != want {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+}
+
+func testScreenshots(t *testing.T, db repository.Repository) {
+	_, err := db.Screenshot("a")
+	checkNotFound(t, err)
+
+	start := time.Now().Add(-time.Second)
+	check(t, db.PutScreenshot("b", []byte("second")))
+	check(t, db.PutScreenshot("a", []byte("first")))
+
+	data, err := db.Screenshot("a")
+	check(t, err)
+
+	if !bytes.Equal(data, []byte("first")) {
+		t.Fatalf("screenshot data = %q, want %q", data, "first")
+	}
+
+	screenshots, err := db.Screenshots()
+	check(t, err)
+
+	if len(screenshots) != 2 || screenshots[0].ID != "a" || screenshots[1].ID != "b" {
+		t.Fatalf("screenshots = %+v, want a and b sorted by ID", screenshots)
+	}
+
+	if screenshots[0].Size != int64(len("first")) || screenshots[0].StoredAt.Before(start) {
+		t.Errorf("screenshot = %+v, want size %d stored now", screenshots[0], len("first"))
+	}
+
+	// Storing it again refreshes the StoredAt.
+	storedAt := screenshots[0].StoredAt
+	time.Sleep(10 * time.Millisecond)
+	check(t, db.PutScreenshot("a", []byte("first")))
+
+	screenshots, err = db.Screenshots()
+	check(t, err)
+
+	if !screenshots[0].StoredAt.After(storedAt) {
+		t.Errorf("stored at = %s, want after %s", screenshots[0].StoredAt, storedAt)
+	}
+
+	check(t, db.DeleteScreenshot("a"))
+
+	_, err = db.Screenshot("a")
+	checkNotFound(t, err)
+
+	screenshots, err = db.Screenshots()
+	check(t, err)
+
+	if len(screenshots) != 1 || screenshots[0].ID != "b" {
+		t.Fatalf("screenshots = %+v, want b", screenshots)
+	}
+}
+
+func testRoles(t *testing.T, db repository.Repository) {
+	roles, err := db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 0 {
+		t.Fatalf("roles = %v, want none", roles)
+	}
+
+	check(t, db.SetRole(1, repository.RoleViewer))
+	check(t, db.SetRole(2, repository.RoleAdmin))
+	check(t, db.SetRole(1, repository.RoleOperator))
+	check(t, db.RemoveRole(2))
+	check(t, db.RemoveRole(3))
+
+	roles, err = db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 1 || roles[1] != repository.RoleOperator {
+		t.Fatalf("roles = %v, want user 1 operator", roles)
+	}
+}
+
+func testCommandMenuChats(t *testing.T, db repository.Repository) {
+	chatIDs, err := db.CommandMenuChats()
+	check(t, err)
+
+	if len(chatIDs) != 0 {
+		t.Fatalf("chats = %v, want none", chatIDs)
+	}
+
+	check(t, db.SetCommandMenuChats([]int64{3, 1, 2}))
+	check(t, db.SetCommandMenuChats([]int64{2, 1}))
+
+	chatIDs, err = db.CommandMenuChats()
+	check(t, err)
+
+	if !slices.Equal(chatIDs, []int64{2, 1}) {
+		t.Fatalf("chats = %v, want the last ones set", chatIDs)
+	}
+
+	check(t, db.SetCommandMenuChats(nil))
+
+	chatIDs, err = db.CommandMenuChats()
+	check(t, err)
+
+	if len(chatIDs) != 0 {
+		t.Fatalf("chats = %v, want none", chatIDs)
+	}
+}
+
+func testOutcomes(t *testing.T, db repository.Repository) {
+	outcomes, err := db.Outcomes()
+	check(t, err)
+
+	if len(outcomes) != 0 {
+		t.Fatalf("outcomes = %v, want none", outcomes)
+	}
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	check(t, db.SetOutcome(repository.Outcome{ResultID: 10, Subscriber: 1, Status: repository.OutcomeMissed, CreatedAt: base}))
+	check(t, db.SetOutcome(repository.Outcome{ResultID: 2, Subscriber: 1, Status: repository.OutcomeBooked, CreatedAt: base}))
+	check(t, db.SetOutcome(repository.Outcome{ResultID: 10, Subscriber: 1, Status: repository.OutcomeBooked, CreatedAt: base.Add(time.Hour)}))
+
+	outcomes, err = db.Outcomes()
+	check(t, err)
+
+	if len(outcomes) != 2 {
+		t.Fatalf("got %d outcomes, want 2", len(outcomes))
+	}
+
+	// Sorted by result ID, and the second outcome for the same result replaced the first one.
+	if outcomes[0].ResultID != 2 || outcomes[1].ResultID != 10 {
+		t.Errorf("outcomes result IDs = %d, %d, want 2, 10", outcomes[0].ResultID, outcomes[1].ResultID)
+	}
+
+	replaced := outcomes[1]
+	if replaced.Status != repository.OutcomeBooked || !replaced.CreatedAt.Equal(base.Add(time.Hour)) {
+		t.Errorf("outcome = %+v, want the booked one", replaced)
+	}
+}
+
+func testProfiles(t *testing.T, db repository.Repository) {
+	profile, err := db.Profile(1)
+	check(t, err)
+
+	if profile != (repository.Profile{}) {
+		t.Fatalf("profile = %+v, want the zero one", profile)
+	}
+
+	want := repository.Profile{
+		Language:         "es",
+		TelegramLanguage: "en",
+		Preferences: repository.Preferences{
+			TimeZone:         "America/Montevideo",
+			QuietStart:       "23:00",
+			QuietEnd:         "07:00",
+			Silent:           true,
+			HideScreenshots:  true,
+			ReminderInterval: time.Hour,
+		},
+		LastNotifiedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		ConfirmedAt:    time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC),
+	}
+	check(t, db.SetProfile(1, want))
+
+	profile, err = db.Profile(1)
+	check(t, err)
+
+	if !profile.LastNotifiedAt.Equal(want.LastNotifiedAt) || !profile.ConfirmedAt.Equal(want.ConfirmedAt) {
+		t.Errorf("profile times = %s, %s, want %s, %s",
+			profile.LastNotifiedAt, profile.ConfirmedAt, want.LastNotifiedAt, want.ConfirmedAt,
+		)
+	}
+
+	profile.LastNotifiedAt, profile.ConfirmedAt = want.LastNotifiedAt, want.ConfirmedAt
+	if profile != want {
+		t.Errorf("profile = %+v, want %+v", profile, want)
+	}
+
+	other, err := db.Profile(2)
+	check(t, err)
+
+	if other != (repository.Profile{}) {
+		t.Errorf("other profile = %+v, want the zero one", other)
+	}
+}
+
+func testUpdateProfile(t *testing.T, db repository.Repository) {
+	check(t, db.SetProfile(1, repository.Profile{Language: "es", Target: "madrid"}))
+
+	profile, err := db.UpdateProfile(1, func(profile *repository.Profile) error {
+		profile.Preferences.Silent = true
+		return nil
+	})
+	check(t, err)
+
+	want := repository.Profile{Language: "es", Target: "madrid", Preferences: repository.Preferences{Silent: true}}
+	if profile != want {
+		t.Errorf("updated profile = %+v, want %+v", profile, want)
+	}
+
+	profile, err = db.Profile(1)
+	check(t, err)
+
+	if profile != want {
+		t.Errorf("profile = %+v, want %+v", profile, want)
+	}
+
+	// The failed updates are not written.
+	errInvalid := errors.New("invalid")
+	_, err = db.UpdateProfile(1, func(profile *repository.Profile) error {
+		profile.Language = "en"
+		return errInvalid
+	})
+	if !errors.Is(err, errInvalid) {
+		t.Fatalf("error = %v, want %v", err, errInvalid)
+	}
+
+	profile, err = db.Profile(1)
+	check(t, err)
+
+	if profile != want {
+		t.Errorf("profile = %+v, want the one before the failed update", profile)
+	}
+
+	// The users without profile get the zero one.
+	profile, err = db.UpdateProfile(2, func(profile *repository.Profile) error {
+		if *profile != (repository.Profile{}) {
+			return fmt.Errorf("profile = %+v, want the zero one", *profile)
+		}
+
+		profile.Language = "en"
+		return nil
+	})
+	check(t, err)
+
+	if profile.Language != "en" {
+		t.Errorf("profile = %+v, want the updated one", profile)
+	}
+}
+
+func testIdempotency(t *testing.T, db repository.Repository) {
+	// Removing what doesn't exist is not an error.
+	check(t, db.RemoveSubscriber(1))
+	check(t, db.RemoveRole(1))
+	check(t, db.DeleteScreenshot("a"))
+
+	for range 2 {
+		check(t, db.AddSubscriber(1))
+		check(t, db.SetRole(1, repository.RoleAdmin))
+		check(t, db.PutScreenshot("a", []byte("data")))
+		check(t, db.ManageDebug(true))
+	}
+
+	subs, err := db.Subscribers()
+	check(t, err)
+
+	if !slices.Equal(subs, []int64{1}) {
+		t.Errorf("subscribers = %v, want [1]", subs)
+	}
+
+	roles, err := db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 1 || roles[1] != repository.RoleAdmin {
+		t.Errorf("roles = %v, want user 1 admin", roles)
+	}
+
+	screenshots, err := db.Screenshots()
+	check(t, err)
+
+	if len(screenshots) != 1 || screenshots[0].Size != int64(len("data")) {
+		t.Errorf("screenshots = %+v, want a single one", screenshots)
+	}
+
+	for range 2 {
+		check(t, db.RemoveSubscriber(1))
+		check(t, db.RemoveRole(1))
+		check(t, db.DeleteScreenshot("a"))
+	}
+
+	subs, err = db.Subscribers()
+	check(t, err)
+
+	if len(subs) != 0 {
+		t.Errorf("subscribers = %v, want none", subs)
+	}
+
+	roles, err = db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 0 {
+		t.Errorf("roles = %v, want none", roles)
+	}
+
+	_, err = db.Screenshot("a")
+	checkNotFound(t, err)
+}
+
+func testNotFound(t *testing.T, db repository.Repository) {
+	_, err := db.Result(0)
+	checkNotFound(t, err)
+
+	_, err = db.Screenshot("")
+	checkNotFound(t, err)
+
+	id, err := db.AddResult(repository.Result{Target: "default", CreatedAt: time.Now()})
+	check(t, err)
+
+	_, err = db.Result(id + 1)
+	checkNotFound(t, err)
+
+	_, err = db.LastResult("other")
+	checkNotFound(t, err)
+
+	_, err = db.LastAvailableResult("other")
+	checkNotFound(t, err)
+
+	// The empty lists are not errors.
+	results, err := db.Results(0)
+	check(t, err)
+
+	if len(results) != 0 {
+		t.Errorf("results = %v, want none", results)
+	}
+
+	hashes, err := db.ScreenshotHashes("other")
+	check(t, err)
+
+	if len(hashes) != 0 {
+		t.Errorf("hashes = %v, want none", hashes)
+	}
+
+	screenshots, err := db.Screenshots()
+	check(t, err)
+
+	if len(screenshots) != 0 {
+		t.Errorf("screenshots = %v, want none", screenshots)
+	}
+}
+
+// testCopies checks the values returned and received are not shared with the repository.
+func testCopies(t *testing.T, db repository.Repository) {
+	data := []byte("first")
+	check(t, db.PutScreenshot("a", data))
+	data[0] = 'x'
+
+	stored, err := db.Screenshot("a")
+	check(t, err)
+
+	if string(stored) != "first" {
+		t.Fatalf("screenshot data = %q, want %q", stored, "first")
+	}
+
+	stored[0] = 'x'
+
+	stored, err = db.Screenshot("a")
+	check(t, err)
+
+	if string(stored) != "first" {
+		t.Errorf("screenshot data = %q, want %q", stored, "first")
+	}
+
+	check(t, db.SetRole(1, repository.RoleViewer))
+
+	roles, err := db.UserRoles()
+	check(t, err)
+
+	roles[2] = repository.RoleOwner
+
+	roles, err = db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 1 {
+		t.Errorf("roles = %v, want user 1 only", roles)
+	}
+
+	check(t, db.AddSubscriber(1))
+
+	subs, err := db.Subscribers()
+	check(t, err)
+
+	subs[0] = 2
+
+	subs, err = db.Subscribers()
+	check(t, err)
+
+	if !slices.Equal(subs, []int64{1}) {
+		t.Errorf("subscribers = %v, want [1]", subs)
+	}
+}
+
+func testAudit(t *testing.T, db repository.Repository) {
+	entries, err := db.AuditEntries(repository.AuditFilter{})
+	check(t, err)
+
+	if len(entries) != 0 {
+		t.Fatalf("audit entries = %v, want none", entries)
+	}
+
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	added := []repository.AuditEntry{
+		{Actor: "telegram:1", Action: "/subscribe", Target: "1", Outcome: repository.AuditSucceeded, CreatedAt: base},
+		{Actor: "telegram:2", Action: "/enabledebug", Target: "2", Outcome: repository.AuditDenied, Error: "unauthorized", CreatedAt: base.Add(time.Minute)},
+		{Actor: repository.AuditActorAPI, Action: "PUT /admin/debug", Outcome: repository.AuditFailed, Error: "invalid body", CreatedAt: base.Add(2 * time.Minute)},
+		{Actor: "telegram:1", Action: "/unsubscribe", Target: "1", Outcome: repository.AuditSucceeded, CreatedAt: base.Add(3 * time.Minute)},
+	}
+
+	for i, entry := range added {
+		id, err := db.AddAuditEntry(entry)
+		check(t, err)
+
+		if id != uint64(i+1) {
+			t.Fatalf("audit entry ID = %d, want %d", id, i+1)
+		}
+
+		added[i].ID = id
+	}
+
+	tests := []struct {
+		name   string
+		filter repository.AuditFilter
+		want   []repository.AuditEntry
+	}{
+		{"all", repository.AuditFilter{}, []repository.AuditEntry{added[3], added[2], added[1], added[0]}},
+		{"limit", repository.AuditFilter{Limit: 2}, []repository.AuditEntry{added[3], added[2]}},
+		{"actor", repository.AuditFilter{Actor: "telegram:1"}, []repository.AuditEntry{added[3], added[0]}},
+		{"action", repository.AuditFilter{Action: "/enabledebug"}, []repository.AuditEntry{added[1]}},
+		{"since", repository.AuditFilter{Since: base.Add(2 * time.Minute)}, []repository.AuditEntry{added[3], added[2]}},
+		{"actor limit", repository.AuditFilter{Actor: "telegram:1", Limit: 1}, []repository.AuditEntry{added[3]}},
+		{"no match", repository.AuditFilter{Actor: "telegram:3"}, nil},
+	}
+
+	for _, tt := range tests {
+		entries, err := db.AuditEntries(tt.filter)
+		check(t, err)
+
+		if len(entries) != len(tt.want) {
+			t.Errorf("%s: got %d audit entries, want %d", tt.name, len(entries), len(tt.want))
+			continue
+		}
+
+		for i, entry := range entries {
+			if !entry.CreatedAt.Equal(tt.want[i].CreatedAt) {
+				t.Errorf("%s: entry %d created at %s, want %s", tt.name, entry.ID, entry.CreatedAt, tt.want[i].CreatedAt)
+			}
+
+			entry.CreatedAt = tt.want[i].CreatedAt
+			if entry != tt.want[i] {
+				t.Errorf("%s: entry = %+v, want %+v", tt.name, entry, tt.want[i])
+			}
+		}
+	}
+}
+
+// concurrently runs f n times in parallel and returns the errors.
+func testDeleteAuditEntries(t *testing.T, db repository.Repository) {
+	base := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		_, err := db.AddAuditEntry(repository.AuditEntry{
+			Actor:     "telegram:1",
+			Action:    "/status",
+			Outcome:   repository.AuditSucceeded,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		})
+		check(t, err)
+	}
+
+	deleted, err := db.DeleteAuditEntriesBefore(base.Add(90 * time.Second))
+	check(t, err)
+
+	if deleted != 2 {
+		t.Errorf("deleted %d audit entries, want 2", deleted)
+	}
+
+	entries, err := db.AuditEntries(repository.AuditFilter{})
+	check(t, err)
+
+	if len(entries) != 1 || entries[0].ID != 3 {
+		t.Fatalf("audit entries = %+v, want the third one", entries)
+	}
+
+	deleted, err = db.DeleteAuditEntriesBefore(base.Add(time.Hour))
+	check(t, err)
+
+	if deleted != 1 {
+		t.Errorf("deleted %d audit entries, want 1", deleted)
+	}
+
+	// The IDs of the deleted entries are not assigned again.
+	id, err := db.AddAuditEntry(repository.AuditEntry{Actor: "telegram:1", Action: "/status", CreatedAt: base})
+	check(t, err)
+
+	if id != 4 {
+		t.Errorf("audit entry ID = %d, want 4", id)
+	}
+}
+
+func concurrently(n int, f func(i int) error) error {
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = f(i)
+		}()
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+func testConcurrentProfileUpdates(t *testing.T, db repository.Repository) {
+	const n = 50
+
+	// Each update reads what the previous ones wrote, none of them is lost.
+	check(t, concurrently(n, func(i int) error {
+		_, err := db.UpdateProfile(1, func(profile *repository.Profile) error {
+			profile.Preferences.ReminderInterval += time.Minute
+			return nil
+		})
+		return err
+	}))
+
+	profile, err := db.Profile(1)
+	check(t, err)
+
+	if profile.Preferences.ReminderInterval != n*time.Minute {
+		t.Errorf("reminder interval = %s, want %s", profile.Preferences.ReminderInterval, n*time.Minute)
+	}
+}
+
+func testConcurrentSubscribers(t *testing.T, db repository.Repository) {
+	const n = 50
+
+	check(t, concurrently(n, func(i int) error {
+		return db.AddSubscriber(int64(i))
+	}))
+
+	subs, err := db.Subscribers()
+	check(t, err)
+
+	if len(subs) != n {
+		t.Fatalf("got %d subscribers, want %d", len(subs), n)
+	}
+
+	// Removing the even ones while adding them again the odd ones.
+	check(t, concurrently(n, func(i int) error {
+		if i%2 == 0 {
+			return db.RemoveSubscriber(int64(i))
+		}
+
+		return db.AddSubscriber(int64(i))
+	}))
+
+	subs, err = db.Subscribers()
+	check(t, err)
+
+	slices.Sort(subs)
+	for i, id := range subs {
+		if id != int64(2*i+1) {
+			t.Fatalf("subscribers = %v, want the odd ones", subs)
+		}
+	}
+
+	if len(subs) != n/2 {
+		t.Fatalf("got %d subscribers, want %d", len(subs), n/2)
+	}
+}
+
+func testConcurrentResults(t *testing.T, db repository.Repository) {
+	const n = 50
+
+	ids := make([]uint64, n)
+	check(t, concurrently(n, func(i int) error {
+		id, err := db.AddResult(repository.Result{Target: "default", CreatedAt: time.Now()})
+		ids[i] = id
+
+		return err
+	}))
+
+	// Every result gets its own ID.
+	slices.Sort(ids)
+	for i, id := range ids {
+		if id != uint64(i+1) {
+			t.Fatalf("result IDs = %v, want 1 to %d", ids, n)
+		}
+	}
+
+	results, err := db.Results(n + 1)
+	check(t, err)
+
+	if len(results) != n {
+		t.Fatalf("got %d results, want %d", len(results), n)
+	}
+
+	last, err := db.LastResult("default")
+	check(t, err)
+
+	if last.ID != n {
+		t.Errorf("last result ID = %d, want %d", last.ID, n)
+	}
+}
+
+func testConcurrentScreenshotHashes(t *testing.T, db repository.Repository) {
+	const n = 50
+
+	lengths := make([]int, n)
+	check(t, concurrently(n, func(i int) error {
+		previous, err := db.AddScreenshotHash("default", repository.ScreenshotHash{Hash: uint64(i)})
+		lengths[i] = len(previous)
+
+		return err
+	}))
+
+	// Each hash is added after reading the history, so every call sees a different one.
+	slices.Sort(lengths)
+	for i, length := range lengths {
+		if length != i {
+			t.Fatalf("previous history lengths = %v, want 0 to %d", lengths, n-1)
+		}
+	}
+}
+
+func testConcurrentWrites(t *testing.T, db repository.Repository) {
+	const n = 50
+
+	check(t, concurrently(n, func(i int) error {
+		userID := int64(i % 5)
+
+		return errors.Join(
+			db.SetRole(userID, repository.Roles[i%len(repository.Roles)]),
+			db.SetProfile(userID, repository.Profile{Language: "es"}),
+			db.SetOutcome(repository.Outcome{ResultID: 1, Subscriber: userID, Status: repository.OutcomeBooked}),
+			addScreenshotHash(db, "default", repository.ScreenshotHash{Hash: uint64(i)}),
+			db.ManageDebug(i%2 == 0),
+			addAuditEntry(db, repository.AuditEntry{Actor: repository.TelegramActor(userID), Action: "/settings"}),
+		)
+	}))
+
+	roles, err := db.UserRoles()
+	check(t, err)
+
+	if len(roles) != 5 {
+		t.Errorf("roles = %v, want 5 users", roles)
+	}
+
+	outcomes, err := db.Outcomes()
+	check(t, err)
+
+	if len(outcomes) != 5 {
+		t.Errorf("got %d outcomes, want 5", len(outcomes))
+	}
+
+	hashes, err := db.ScreenshotHashes("default")
+	check(t, err)
+
+	if len(hashes) != n {
+		t.Errorf("got %d hashes, want %d", len(hashes), n)
+	}
+
+	entries, err := db.AuditEntries(repository.AuditFilter{})
+	check(t, err)
+
+	if len(entries) != n {
+		t.Errorf("got %d audit entries, want %d", len(entries), n)
+	}
+}
+
+func addScreenshotHash(db repository.Repository, target string, hash repository.ScreenshotHash) error {
+	_, err := db.AddScreenshotHash(target, hash)
+	return err
+}
+
+func addAuditEntry(db repository.Repository, entry repository.AuditEntry) error {
+	_, err := db.AddAuditEntry(entry)
+	return err
+}
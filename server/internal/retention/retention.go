@@ -0,0 +1,79 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Policy deletes the records older than MaxAge every Schedule, a zero MaxAge keeps them.
+type Policy struct {
+	MaxAge   time.Duration
+	Schedule time.Duration
+}
+
+// Job prunes a kind of database records, e.g. the results or the audit entries, following its policy.
+type Job struct {
+	name         string
+	deleteBefore func(before time.Time) (int, error)
+
+	mu     sync.Mutex
+	policy Policy
+}
+
+// NewJob returns the job that prunes the given records, deleteBefore deletes the ones created before the given time
+// and returns how many were deleted.
+func NewJob(name string, deleteBefore func(before time.Time) (int, error), policy Policy) *Job {
+	return &Job{name: name, deleteBefore: deleteBefore, policy: policy}
+}
+
+// SetPolicy replaces the retention policy, it's applied from the next prune on.
+func (j *Job) SetPolicy(policy Policy) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.policy = policy
+}
+
+func (j *Job) retentionPolicy() Policy {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.policy
+}
+
+// Prune deletes the records older than the policy max age.
+func (j *Job) Prune() error {
+	policy := j.retentionPolicy()
+	if policy.MaxAge <= 0 {
+		return nil
+	}
+
+	deleted, err := j.deleteBefore(time.Now().Add(-policy.MaxAge))
+	if err != nil {
+		return fmt.Errorf("error deleting %s: %w", j.name, err)
+	}
+
+	if deleted > 0 {
+		slog.Info("old "+j.name+" deleted", slog.Int("deleted", deleted))
+	}
+
+	return nil
+}
+
+// Run prunes the records following the policy schedule until the context is done.
+func (j *Job) Run(ctx context.Context) {
+	for {
+		if err := j.Prune(); err != nil {
+			slog.Error("error pruning "+j.name, slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(j.retentionPolicy().Schedule):
+		}
+	}
+}
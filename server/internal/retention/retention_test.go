@@ -0,0 +1,49 @@
+package retention
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestPrune(t *testing.T) {
+	db := memory.NewDB()
+	now := time.Now()
+
+	for _, createdAt := range []time.Time{now.Add(-48 * time.Hour), now.Add(-time.Hour)} {
+		_, err := db.AddAuditEntry(repository.AuditEntry{Actor: repository.AuditActorAPI, Action: "GET /audit", CreatedAt: createdAt})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// The zero max age keeps the records.
+	job := NewJob("audit entries", db.DeleteAuditEntriesBefore, Policy{Schedule: time.Hour})
+	if err := job.Prune(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkEntries(t, db, 2)
+
+	job.SetPolicy(Policy{MaxAge: 24 * time.Hour, Schedule: time.Hour})
+	if err := job.Prune(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	checkEntries(t, db, 1)
+}
+
+func checkEntries(t *testing.T, db *memory.DB, want int) {
+	t.Helper()
+
+	entries, err := db.AuditEntries(repository.AuditFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(entries) != want {
+		t.Errorf("got %d audit entries, want %d", len(entries), want)
+	}
+}
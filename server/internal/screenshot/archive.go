@@ -0,0 +1,155 @@
+package screenshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// RetentionPolicy limits the archived screenshots by age and by total (compressed) size, zero values disable the
+// corresponding limit. It's applied every Schedule.
+type RetentionPolicy struct {
+	MaxAge   time.Duration
+	MaxSize  int64
+	Schedule time.Duration
+}
+
+// Archive stores the scrapper screenshots compressed and addressed by their content.
+type Archive struct {
+	db repository.Repository
+
+	mu     sync.Mutex
+	policy RetentionPolicy
+}
+
+func NewArchive(db repository.Repository, policy RetentionPolicy) *Archive {
+	return &Archive{db: db, policy: policy}
+}
+
+// SetPolicy replaces the retention policy, it's applied from the next prune on.
+func (a *Archive) SetPolicy(policy RetentionPolicy) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.policy = policy
+}
+
+func (a *Archive) retentionPolicy() RetentionPolicy {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return a.policy
+}
+
+// Store archives the image and returns its ID.
+func (a *Archive) Store(image []byte) (string, error) {
+	sum := sha256.Sum256(image)
+	id := hex.EncodeToString(sum[:])
+
+	var compressed bytes.Buffer
+	w := gzip.NewWriter(&compressed)
+	if _, err := w.Write(image); err != nil {
+		return "", fmt.Errorf("error compressing screenshot: %w", err)
+	}
+
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("error compressing screenshot: %w", err)
+	}
+
+	if err := a.db.PutScreenshot(id, compressed.Bytes()); err != nil {
+		return "", fmt.Errorf("error storing screenshot: %w", err)
+	}
+
+	return id, nil
+}
+
+// Load returns the original image, or repository.ErrNotFound if it was never archived or was already pruned.
+func (a *Archive) Load(id string) ([]byte, error) {
+	compressed, err := a.db.Screenshot(id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting screenshot: %w", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing screenshot: %w", err)
+	}
+	defer r.Close()
+
+	image, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing screenshot: %w", err)
+	}
+
+	return image, nil
+}
+
+// Prune deletes the screenshots older than the policy max age and then the oldest ones until the archive fits the
+// policy max size.
+func (a *Archive) Prune() error {
+	policy := a.retentionPolicy()
+
+	screenshots, err := a.db.Screenshots()
+	if err != nil {
+		return fmt.Errorf("error getting screenshots: %w", err)
+	}
+
+	slices.SortFunc(screenshots, func(x, y repository.Screenshot) int {
+		return x.StoredAt.Compare(y.StoredAt)
+	})
+
+	var totalSize int64
+	for _, screenshot := range screenshots {
+		totalSize += screenshot.Size
+	}
+
+	deleted := 0
+	for _, screenshot := range screenshots {
+		expired := policy.MaxAge > 0 && time.Since(screenshot.StoredAt) > policy.MaxAge
+		oversize := policy.MaxSize > 0 && totalSize > policy.MaxSize
+		if !expired && !oversize {
+			break
+		}
+
+		if err := a.db.DeleteScreenshot(screenshot.ID); err != nil {
+			return fmt.Errorf("error deleting screenshot: %w", err)
+		}
+
+		totalSize -= screenshot.Size
+		deleted++
+	}
+
+	if deleted > 0 {
+		slog.Info("screenshot archive pruned",
+			slog.Int("deleted", deleted),
+			slog.Int64("size", totalSize),
+		)
+	}
+
+	return nil
+}
+
+// RunRetention prunes the archive following the policy schedule until the context is done.
+func (a *Archive) RunRetention(ctx context.Context) {
+	for {
+		if err := a.Prune(); err != nil {
+			slog.Error("error pruning screenshot archive", slog.Any("error", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(a.retentionPolicy().Schedule):
+		}
+	}
+}
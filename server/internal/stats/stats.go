@@ -0,0 +1,124 @@
+package stats
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// DefaultPeriod is how far back the statistics look unless another period is asked.
+const DefaultPeriod = 30 * 24 * time.Hour
+
+// Report holds the availability statistics of each target since the given time. The weekdays and hours are in
+// the report time zone.
+type Report struct {
+	Since    time.Time `json:"since"`
+	TimeZone string    `json:"time_zone"`
+	Targets  []Target  `json:"targets"`
+}
+
+// Target is the availability statistics of a target. An availability window is a run of checks that found
+// available hours, it ends with the first check that doesn't.
+type Target struct {
+	Target string `json:"target"`
+	Checks int    `json:"checks"`
+	// Errors are the failed checks, not included in Checks.
+	Errors int `json:"errors"`
+	// Available reports whether the latest check found available hours.
+	Available bool `json:"available"`
+	Windows   int  `json:"windows"`
+	// WindowsByWeekday and WindowsByHour count the windows by the weekday and hour they started.
+	WindowsByWeekday map[string]int `json:"windows_by_weekday"`
+	WindowsByHour    [24]int        `json:"windows_by_hour"`
+	// AverageWindowSeconds only considers the finished windows.
+	AverageWindowSeconds float64    `json:"average_window_seconds"`
+	LastAvailableAt      *time.Time `json:"last_available_at,omitempty"`
+	// SinceLastAvailableSeconds is the time since the last check that found available hours.
+	SinceLastAvailableSeconds *float64 `json:"since_last_available_seconds,omitempty"`
+}
+
+// AverageWindow returns the average duration of the finished windows.
+func (t Target) AverageWindow() time.Duration {
+	return time.Duration(t.AverageWindowSeconds * float64(time.Second))
+}
+
+// SinceLastAvailable returns the time since the last availability, false if there was none in the period.
+func (t Target) SinceLastAvailable() (time.Duration, bool) {
+	if t.SinceLastAvailableSeconds == nil {
+		return 0, false
+	}
+
+	return time.Duration(*t.SinceLastAvailableSeconds * float64(time.Second)), true
+}
+
+// Compute aggregates the results, in any order, into the statistics of each target sorted by name. The failed
+// checks are only counted as errors, they neither start nor end an availability window.
+func Compute(results []repository.Result, since, now time.Time, location *time.Location) Report {
+	results = slices.Clone(results)
+	slices.SortFunc(results, func(a, b repository.Result) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	byTarget := make(map[string]*Target)
+	windowStart := make(map[string]time.Time)
+	finished := make(map[string]int)
+	total := make(map[string]time.Duration)
+
+	for _, result := range results {
+		target, ok := byTarget[result.Target]
+		if !ok {
+			target = &Target{Target: result.Target, WindowsByWeekday: make(map[string]int)}
+			byTarget[result.Target] = target
+		}
+
+		status := result.CheckStatus()
+		if status == repository.ResultFailed {
+			target.Errors++
+			continue
+		}
+
+		target.Checks++
+
+		available := status == repository.ResultAvailable
+		switch {
+		case available && !target.Available:
+			start := result.CreatedAt.In(location)
+			windowStart[result.Target] = start
+			target.Windows++
+			target.WindowsByWeekday[start.Weekday().String()]++
+			target.WindowsByHour[start.Hour()]++
+
+		case !available && target.Available:
+			finished[result.Target]++
+			total[result.Target] += result.CreatedAt.Sub(windowStart[result.Target])
+		}
+
+		target.Available = available
+		if available {
+			lastAvailableAt := result.CreatedAt
+			target.LastAvailableAt = &lastAvailableAt
+		}
+	}
+
+	report := Report{Since: since, TimeZone: location.String(), Targets: []Target{}}
+	for name, target := range byTarget {
+		if finished[name] > 0 {
+			target.AverageWindowSeconds = (total[name] / time.Duration(finished[name])).Seconds()
+		}
+
+		if target.LastAvailableAt != nil {
+			sinceLastAvailable := now.Sub(*target.LastAvailableAt).Seconds()
+			target.SinceLastAvailableSeconds = &sinceLastAvailable
+		}
+
+		report.Targets = append(report.Targets, *target)
+	}
+
+	slices.SortFunc(report.Targets, func(a, b Target) int {
+		return cmp.Compare(a.Target, b.Target)
+	})
+
+	return report
+}
@@ -0,0 +1,99 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+func TestCompute(t *testing.T) {
+	// Monday 2026-10-05 10:00 UTC.
+	start := time.Date(2026, 10, 5, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	result := func(target string, minutes int, status repository.ResultStatus) repository.Result {
+		return repository.Result{Target: target, CreatedAt: at(minutes), Status: status}
+	}
+
+	tests := []struct {
+		name    string
+		results []repository.Result
+		want    Target
+	}{
+		{
+			name: "finished window",
+			results: []repository.Result{
+				result("madrid", 0, repository.ResultUnavailable),
+				result("madrid", 5, repository.ResultAvailable),
+				result("madrid", 10, repository.ResultAvailable),
+				result("madrid", 35, repository.ResultUnavailable),
+			},
+			want: Target{Checks: 4, Windows: 1, AverageWindowSeconds: (30 * time.Minute).Seconds()},
+		},
+		{
+			name: "failed checks don't end the window",
+			results: []repository.Result{
+				result("madrid", 0, repository.ResultAvailable),
+				result("madrid", 5, repository.ResultFailed),
+				result("madrid", 10, repository.ResultAvailable),
+			},
+			want: Target{Checks: 2, Errors: 1, Available: true, Windows: 1},
+		},
+		{
+			name: "failed checks aren't unavailable",
+			results: []repository.Result{
+				result("madrid", 0, repository.ResultFailed),
+				result("madrid", 5, repository.ResultFailed),
+			},
+			want: Target{Errors: 2},
+		},
+		{
+			name: "results without status",
+			results: []repository.Result{
+				{Target: "madrid", CreatedAt: at(10), Debug: true},
+				{Target: "madrid", CreatedAt: at(0)},
+			},
+			want: Target{Checks: 2, Windows: 1, AverageWindowSeconds: (10 * time.Minute).Seconds()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Compute(tt.results, start, at(60), time.UTC)
+			if len(report.Targets) != 1 {
+				t.Fatalf("targets = %+v, want one", report.Targets)
+			}
+
+			got := report.Targets[0]
+			if got.Checks != tt.want.Checks || got.Errors != tt.want.Errors || got.Available != tt.want.Available ||
+				got.Windows != tt.want.Windows || got.AverageWindowSeconds != tt.want.AverageWindowSeconds {
+				t.Errorf("target = %+v, want %+v", got, tt.want)
+			}
+
+			if tt.want.Windows > 0 && (got.WindowsByWeekday["Monday"] != tt.want.Windows || got.WindowsByHour[10] != tt.want.Windows) {
+				t.Errorf("windows by weekday = %v, by hour = %v, want them on Monday at 10", got.WindowsByWeekday, got.WindowsByHour)
+			}
+		})
+	}
+}
+
+func TestComputeTargets(t *testing.T) {
+	now := time.Date(2026, 10, 5, 12, 0, 0, 0, time.UTC)
+	results := []repository.Result{
+		{Target: "madrid", CreatedAt: now.Add(-time.Hour), Status: repository.ResultAvailable},
+		{Target: "barcelona", CreatedAt: now.Add(-time.Hour), Status: repository.ResultUnavailable},
+	}
+
+	report := Compute(results, now.Add(-DefaultPeriod), now, time.UTC)
+	if len(report.Targets) != 2 || report.Targets[0].Target != "barcelona" || report.Targets[1].Target != "madrid" {
+		t.Fatalf("targets = %+v, want barcelona and madrid", report.Targets)
+	}
+
+	if since, ok := report.Targets[1].SinceLastAvailable(); !ok || since != time.Hour {
+		t.Errorf("SinceLastAvailable() = %s, %t, want 1h", since, ok)
+	}
+
+	if _, ok := report.Targets[0].SinceLastAvailable(); ok {
+		t.Error("SinceLastAvailable() found an availability, want none")
+	}
+}
@@ -0,0 +1,157 @@
+package subscribers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// Formats lists the supported export and import formats.
+var Formats = []string{"json", "csv"}
+
+var csvHeader = []string{
+	"id", "language", "time_zone", "quiet_start", "quiet_end", "silent", "hide_screenshots", "reminder_interval",
+	"confirmed_at", "target",
+}
+
+// legacyCSVHeader is the header of the exports made before the target was exported, which are still imported.
+var legacyCSVHeader = csvHeader[:len(csvHeader)-1]
+
+// Encode writes the subscribers in the given format.
+func Encode(w io.Writer, format string, subscribers []Subscriber) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(subscribers)
+
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+
+		for _, s := range subscribers {
+			var confirmedAt string
+			if !s.ConfirmedAt.IsZero() {
+				confirmedAt = s.ConfirmedAt.Format(time.RFC3339)
+			}
+
+			err := writer.Write([]string{
+				strconv.FormatInt(s.ID, 10),
+				s.Language,
+				s.Preferences.TimeZone,
+				s.Preferences.QuietStart,
+				s.Preferences.QuietEnd,
+				strconv.FormatBool(s.Preferences.Silent),
+				strconv.FormatBool(s.Preferences.HideScreenshots),
+				s.Preferences.ReminderInterval.String(),
+				confirmedAt,
+				s.Target,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("unknown format '%s'", format)
+}
+
+// Decode reads the subscribers in the given format, the CSV must have the Encode header or the legacy one.
+func Decode(r io.Reader, format string) ([]Subscriber, error) {
+	switch format {
+	case "json":
+		var subscribers []Subscriber
+		if err := json.NewDecoder(r).Decode(&subscribers); err != nil {
+			return nil, fmt.Errorf("error decoding JSON: %w", err)
+		}
+
+		return subscribers, nil
+
+	case "csv":
+		records, err := csv.NewReader(r).ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("error decoding CSV: %w", err)
+		}
+
+		if len(records) == 0 || !slices.Equal(records[0], csvHeader) && !slices.Equal(records[0], legacyCSVHeader) {
+			return nil, fmt.Errorf("invalid CSV header, expected: %v", csvHeader)
+		}
+
+		subscribers := make([]Subscriber, 0, len(records)-1)
+		for i, record := range records[1:] {
+			subscriber, err := decodeCSV(record)
+			if err != nil {
+				// The first record is the header.
+				return nil, fmt.Errorf("invalid CSV record %d: %w", i+2, err)
+			}
+
+			subscribers = append(subscribers, subscriber)
+		}
+
+		return subscribers, nil
+	}
+
+	return nil, fmt.Errorf("unknown format '%s'", format)
+}
+
+func decodeCSV(record []string) (Subscriber, error) {
+	id, err := strconv.ParseInt(record[0], 10, 64)
+	if err != nil {
+		return Subscriber{}, fmt.Errorf("invalid id: %w", err)
+	}
+
+	silent, err := strconv.ParseBool(record[5])
+	if err != nil {
+		return Subscriber{}, fmt.Errorf("invalid silent: %w", err)
+	}
+
+	hideScreenshots, err := strconv.ParseBool(record[6])
+	if err != nil {
+		return Subscriber{}, fmt.Errorf("invalid hide_screenshots: %w", err)
+	}
+
+	reminderInterval, err := time.ParseDuration(record[7])
+	if err != nil {
+		return Subscriber{}, fmt.Errorf("invalid reminder_interval: %w", err)
+	}
+
+	var confirmedAt time.Time
+	if record[8] != "" {
+		confirmedAt, err = time.Parse(time.RFC3339, record[8])
+		if err != nil {
+			return Subscriber{}, fmt.Errorf("invalid confirmed_at: %w", err)
+		}
+	}
+
+	// The legacy records have no target.
+	var target string
+	if len(record) > 9 {
+		target = record[9]
+	}
+
+	return Subscriber{
+		ID:       id,
+		Language: record[1],
+		Preferences: repository.Preferences{
+			TimeZone:         record[2],
+			QuietStart:       record[3],
+			QuietEnd:         record[4],
+			Silent:           silent,
+			HideScreenshots:  hideScreenshots,
+			ReminderInterval: reminderInterval,
+		},
+		Target:      target,
+		ConfirmedAt: confirmedAt,
+	}, nil
+}
@@ -0,0 +1,201 @@
+package subscribers
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/i18n"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+// Subscriber is an exported subscriber, with the profile settings chosen by them. The rest of the profile is the
+// deployment state and is not exported.
+type Subscriber struct {
+	ID          int64                  `json:"id"`
+	Language    string                 `json:"language,omitempty"`
+	Preferences repository.Preferences `json:"preferences"`
+	Target      string                 `json:"target,omitempty"`
+	ConfirmedAt time.Time              `json:"confirmed_at"`
+}
+
+// Conflict is an imported setting that differs from the one the subscriber already has, which is kept.
+type Conflict struct {
+	ID       int64  `json:"id"`
+	Field    string `json:"field"`
+	Current  string `json:"current"`
+	Imported string `json:"imported"`
+}
+
+// Report summarizes an import, Added are the new subscribers and Updated the existing ones that got new settings.
+type Report struct {
+	DryRun    bool       `json:"dry_run"`
+	Added     int        `json:"added"`
+	Updated   int        `json:"updated"`
+	Unchanged int        `json:"unchanged"`
+	Conflicts []Conflict `json:"conflicts"`
+}
+
+// Export returns the subscribers, sorted by ID.
+func Export(db repository.Repository) ([]Subscriber, error) {
+	ids, err := db.Subscribers()
+	if err != nil {
+		return nil, fmt.Errorf("error getting subscribers: %w", err)
+	}
+
+	slices.Sort(ids)
+
+	subscribers := make([]Subscriber, 0, len(ids))
+	for _, id := range ids {
+		profile, err := db.Profile(id)
+		if err != nil {
+			return nil, fmt.Errorf("error getting profile of subscriber %d: %w", id, err)
+		}
+
+		subscribers = append(subscribers, Subscriber{
+			ID:          id,
+			Language:    profile.Language,
+			Preferences: profile.Preferences,
+			Target:      profile.Target,
+			ConfirmedAt: profile.ConfirmedAt,
+		})
+	}
+
+	return subscribers, nil
+}
+
+// ErrInvalid is returned importing subscribers that fail the validation, nothing is written then.
+var ErrInvalid = errors.New("invalid subscribers")
+
+// Import merges the subscribers into the repository, nothing is written on a dry run. The settings the existing
+// subscribers don't have are imported, while the ones that differ are reported as conflicts and kept, so
+// importing the same subscribers again changes nothing. Every subscriber is validated before writing any of them.
+func Import(db repository.Repository, subscribers []Subscriber, dryRun bool) (Report, error) {
+	if err := Validate(subscribers); err != nil {
+		return Report{}, err
+	}
+
+	current, err := db.Subscribers()
+	if err != nil {
+		return Report{}, fmt.Errorf("error getting subscribers: %w", err)
+	}
+
+	report := Report{DryRun: dryRun, Conflicts: []Conflict{}}
+	for _, subscriber := range subscribers {
+		// The update may be retried, only its last run is reported.
+		var conflicts []Conflict
+		var changed bool
+		apply := func(profile *repository.Profile) error {
+			var merged repository.Profile
+			merged, conflicts = merge(*profile, subscriber)
+			changed = merged != *profile
+			*profile = merged
+			return nil
+		}
+
+		if dryRun {
+			profile, err := db.Profile(subscriber.ID)
+			if err != nil {
+				return report, fmt.Errorf("error getting profile of subscriber %d: %w", subscriber.ID, err)
+			}
+
+			_ = apply(&profile)
+		} else if _, err := db.UpdateProfile(subscriber.ID, apply); err != nil {
+			return report, fmt.Errorf("error updating profile of subscriber %d: %w", subscriber.ID, err)
+		}
+
+		report.Conflicts = append(report.Conflicts, conflicts...)
+
+		subscribed := slices.Contains(current, subscriber.ID)
+		switch {
+		case !subscribed:
+			report.Added++
+		case changed:
+			report.Updated++
+		default:
+			report.Unchanged++
+		}
+
+		if dryRun || subscribed {
+			continue
+		}
+
+		if err := db.AddSubscriber(subscriber.ID); err != nil {
+			return report, fmt.Errorf("error adding subscriber %d: %w", subscriber.ID, err)
+		}
+	}
+
+	return report, nil
+}
+
+// Validate checks the subscribers have a unique ID and the settings /settings and /language accept.
+func Validate(subscribers []Subscriber) error {
+	ids := make(map[int64]bool, len(subscribers))
+	for _, subscriber := range subscribers {
+		if ids[subscriber.ID] {
+			return fmt.Errorf("%w: duplicate subscriber %d", ErrInvalid, subscriber.ID)
+		}
+
+		ids[subscriber.ID] = true
+
+		if subscriber.Language != "" && !i18n.Supported(subscriber.Language) {
+			return fmt.Errorf("%w: unsupported language '%s' of subscriber %d, use one of: %s",
+				ErrInvalid, subscriber.Language, subscriber.ID, strings.Join(i18n.Languages(), ", "))
+		}
+
+		if err := subscriber.Preferences.Validate(); err != nil {
+			return fmt.Errorf("%w: subscriber %d: %w", ErrInvalid, subscriber.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// merge sets the imported settings the profile doesn't have, keeping the latest confirmation.
+func merge(profile repository.Profile, subscriber Subscriber) (repository.Profile, []Conflict) {
+	var conflicts []Conflict
+
+	switch {
+	case profile.Language == "":
+		profile.Language = subscriber.Language
+	case subscriber.Language != "" && subscriber.Language != profile.Language:
+		conflicts = append(conflicts, Conflict{
+			ID:       subscriber.ID,
+			Field:    "language",
+			Current:  profile.Language,
+			Imported: subscriber.Language,
+		})
+	}
+
+	switch {
+	case profile.Preferences == repository.Preferences{}:
+		profile.Preferences = subscriber.Preferences
+	case subscriber.Preferences != repository.Preferences{} && subscriber.Preferences != profile.Preferences:
+		conflicts = append(conflicts, Conflict{
+			ID:       subscriber.ID,
+			Field:    "preferences",
+			Current:  fmt.Sprintf("%+v", profile.Preferences),
+			Imported: fmt.Sprintf("%+v", subscriber.Preferences),
+		})
+	}
+
+	switch {
+	case profile.Target == "":
+		profile.Target = subscriber.Target
+	case subscriber.Target != "" && subscriber.Target != profile.Target:
+		conflicts = append(conflicts, Conflict{
+			ID:       subscriber.ID,
+			Field:    "target",
+			Current:  profile.Target,
+			Imported: subscriber.Target,
+		})
+	}
+
+	if subscriber.ConfirmedAt.After(profile.ConfirmedAt) {
+		profile.ConfirmedAt = subscriber.ConfirmedAt
+	}
+
+	return profile, conflicts
+}
@@ -0,0 +1,223 @@
+package subscribers
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/skryde/booking-check/server/internal/platform/storage/memory"
+	"github.com/skryde/booking-check/server/internal/repository"
+)
+
+var confirmedAt = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// newDB returns a repository with subscriber 1, who chose Spanish, quiet hours and the madrid target.
+func newDB(t *testing.T) *memory.DB {
+	t.Helper()
+
+	db := memory.NewDB()
+	if err := db.AddSubscriber(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := db.SetProfile(1, repository.Profile{
+		Language:    "es",
+		Preferences: repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"},
+		Target:      "madrid",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return db
+}
+
+func TestImport(t *testing.T) {
+	imported := []Subscriber{
+		{ID: 1, Language: "en", Target: "barcelona", ConfirmedAt: confirmedAt},
+		{ID: 2, Language: "en", Preferences: repository.Preferences{TimeZone: "Europe/Madrid"}, Target: "barcelona"},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		db := newDB(t)
+
+		report, err := Import(db, imported, dryRun)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		want := Report{
+			DryRun:  dryRun,
+			Added:   1,
+			Updated: 1,
+			Conflicts: []Conflict{
+				{ID: 1, Field: "language", Current: "es", Imported: "en"},
+				{ID: 1, Field: "target", Current: "madrid", Imported: "barcelona"},
+			},
+		}
+		if !reflect.DeepEqual(report, want) {
+			t.Errorf("dry run %t: report = %+v, want %+v", dryRun, report, want)
+		}
+
+		subscribers, err := db.Subscribers()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		wantSubscribers := []int64{1, 2}
+		if dryRun {
+			wantSubscribers = []int64{1}
+		}
+
+		slices.Sort(subscribers)
+		if !slices.Equal(subscribers, wantSubscribers) {
+			t.Errorf("dry run %t: subscribers = %v, want %v", dryRun, subscribers, wantSubscribers)
+		}
+
+		profile, err := db.Profile(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if confirmed := profile.ConfirmedAt.Equal(confirmedAt); confirmed == dryRun {
+			t.Errorf("dry run %t: confirmed at = %s", dryRun, profile.ConfirmedAt)
+		}
+
+		if profile.Language != "es" || profile.Target != "madrid" {
+			t.Errorf("dry run %t: language = %q and target = %q, want the current ones", dryRun, profile.Language, profile.Target)
+		}
+
+		added, err := db.Profile(2)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if imported := added.Target == "barcelona"; imported == dryRun {
+			t.Errorf("dry run %t: target = %q", dryRun, added.Target)
+		}
+	}
+}
+
+func TestImportAgain(t *testing.T) {
+	db := newDB(t)
+	imported := []Subscriber{{ID: 2, Language: "en"}}
+
+	if _, err := Import(db, imported, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	report, err := Import(db, imported, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if want := (Report{Unchanged: 1, Conflicts: []Conflict{}}); !reflect.DeepEqual(report, want) {
+		t.Errorf("report = %+v, want %+v", report, want)
+	}
+}
+
+func TestImportInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		subscriber Subscriber
+	}{
+		{"duplicate", Subscriber{ID: 2}},
+		{"unsupported language", Subscriber{ID: 3, Language: "fr"}},
+		{"unknown time zone", Subscriber{ID: 3, Preferences: repository.Preferences{TimeZone: "Mars/Olympus"}}},
+		{"local time zone", Subscriber{ID: 3, Preferences: repository.Preferences{TimeZone: "Local"}}},
+		{"invalid quiet hours", Subscriber{ID: 3, Preferences: repository.Preferences{QuietStart: "9am", QuietEnd: "10:00"}}},
+		{"missing quiet end", Subscriber{ID: 3, Preferences: repository.Preferences{QuietStart: "22:00"}}},
+		{"negative reminder interval", Subscriber{ID: 3, Preferences: repository.Preferences{ReminderInterval: -time.Hour}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+
+			// The valid subscriber goes first, it must not be written either.
+			_, err := Import(db, []Subscriber{{ID: 2, Language: "en"}, tt.subscriber}, false)
+			if !errors.Is(err, ErrInvalid) {
+				t.Fatalf("error = %v, want %v", err, ErrInvalid)
+			}
+
+			subscribers, err := db.Subscribers()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !slices.Equal(subscribers, []int64{1}) {
+				t.Errorf("subscribers = %v, want them unchanged", subscribers)
+			}
+
+			profile, err := db.Profile(2)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if profile != (repository.Profile{}) {
+				t.Errorf("profile = %+v, want it unchanged", profile)
+			}
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	db := newDB(t)
+	if err := db.AddSubscriber(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The deployment state isn't exported.
+	err := db.SetProfile(2, repository.Profile{TelegramLanguage: "en", ConfirmedAt: confirmedAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	exported, err := Export(db)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Subscriber{
+		{ID: 1, Language: "es", Preferences: repository.Preferences{QuietStart: "23:00", QuietEnd: "07:00"}, Target: "madrid"},
+		{ID: 2, ConfirmedAt: confirmedAt},
+	}
+	if !reflect.DeepEqual(exported, want) {
+		t.Fatalf("Export() = %+v, want %+v", exported, want)
+	}
+
+	for _, format := range Formats {
+		var buf bytes.Buffer
+		if err := Encode(&buf, format, exported); err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		decoded, err := Decode(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", format, err)
+		}
+
+		if !reflect.DeepEqual(decoded, want) {
+			t.Errorf("%s: Decode() = %+v, want %+v", format, decoded, want)
+		}
+	}
+}
+
+func TestDecodeLegacyCSV(t *testing.T) {
+	legacy := "id,language,time_zone,quiet_start,quiet_end,silent,hide_screenshots,reminder_interval,confirmed_at\n" +
+		"1,es,,,,false,false,0s,2026-10-01T12:00:00Z\n"
+
+	decoded, err := Decode(strings.NewReader(legacy), "csv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []Subscriber{{ID: 1, Language: "es", ConfirmedAt: confirmedAt}}
+	if !reflect.DeepEqual(decoded, want) {
+		t.Errorf("Decode() = %+v, want %+v", decoded, want)
+	}
+}